@@ -0,0 +1,283 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named bind.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package argv
+
+import (
+         "fmt"
+         "time"
+         "regexp"
+         "reflect"
+         "strconv"
+       )
+
+/*
+  Maps the names usable in the "checker" struct tag (see StructUsage())
+  to ArgCheckers. You may add your own ArgCheckers to this map.
+*/
+var Checkers = map[string]ArgChecker{
+  "none": ArgNone,
+  "optional": ArgOptional,
+  "required": ArgRequired,
+  "nonempty": ArgNonEmpty,
+  "int": ArgInt,
+  "unimpl": ArgUnimpl,
+  "float": ArgFloat,
+  "duration": ArgDuration,
+  "bool": ArgBool,
+  "size": ArgSize,
+  "file": ArgExistingFile,
+  "regexp": ArgRegexp,
+  "keyvalue": ArgKeyValue,
+}
+
+/*
+  Builds a Usage from the tags of the struct config points to.
+  Every exported field with a "short" or "long" tag becomes one OptionInfo.
+  The following tags are understood:
+  
+    short: the OptionInfo.Short characters, e.g. `short:"v"`
+    
+    long: the OptionInfo.Long name, e.g. `long:"verbose"`
+    
+    help: the explanation that goes into the last column of the Help text.
+    
+    arg: the placeholder for the argument in the Help text. Default is "<arg>".
+    
+    checker: the name of an ArgChecker from the Checkers map. If this tag is
+             missing, the ArgChecker is chosen based on the field type:
+             ArgNone for bool, ArgInt for int, ArgFloat for float64,
+             ArgDuration for time.Duration, ArgRegexp for *regexp.Regexp and
+             ArgRequired for everything else. Slices are treated like their
+             element type.
+  
+  The Help text is generated from the above tags in the usual
+  "  -s <arg>, \t--long=<arg>  \thelp" table format.
+  The Id of each OptionInfo is the number of the field among the tagged fields,
+  starting at 0, and State is 1.
+  The returned Usage contains no dummy entry for unknown options and no usage
+  header. You will usually want to prepend something like
+  
+    {-1, 0, "", "", argv.ArgUnknown, "USAGE: program [options]\n\nOptions:"}
+  
+  Use FillStruct() to store the result of Parse() into the struct.
+*/
+func StructUsage(config interface{}) (Usage, error) {
+  fields, err := structFields(config)
+  if err != nil { return nil, err }
+  
+  usage := make(Usage, 0, len(fields))
+  for id, field := range fields {
+    short := field.Tag.Get("short")
+    long := field.Tag.Get("long")
+    
+    var check ArgChecker
+    if name := field.Tag.Get("checker"); name != "" {
+      check = Checkers[name]
+      if check == nil {
+        return nil, fmt.Errorf("StructUsage(): Unknown checker '%v' for field %v", name, field.Name)
+      }
+    } else {
+      check = defaultChecker(field.Type)
+    }
+    
+    arg := ""
+    if reflect.ValueOf(check).Pointer() != reflect.ValueOf(ArgNone).Pointer() {
+      arg = field.Tag.Get("arg")
+      if arg == "" { arg = "<arg>" }
+    }
+    
+    help := "  "
+    if short != "" {
+      help += "-" + short[0:1]
+      if arg != "" { help += " " + arg }
+      if long != "" { help += ", " }
+    }
+    help += "\t"
+    if long != "" {
+      help += "--" + long
+      if arg != "" { help += "=" + arg }
+    }
+    help += "  \t" + field.Tag.Get("help")
+    
+    usage = append(usage, OptionInfo{id, 1, short, long, check, help})
+  }
+  
+  return usage, nil
+}
+
+/*
+  Stores options (as returned by Parse() for a Usage created by StructUsage())
+  into the fields of the struct config points to. Fields whose option is
+  not present in options are left unchanged, so you can initialize the struct
+  with default values before calling FillStruct().
+  
+  Fields are filled according to their type:
+  
+    bool: true if the option is present, unless the ArgChecker stores a bool
+          in Value (e.g. ArgBool), in which case the Value of the last occurrence
+          is used (e.g. --flag=false => false).
+    
+    slices: one element for each occurrence of the option (following Next()).
+    
+    int (if the ArgChecker is ArgNone): the number of occurrences of the option
+                                        (e.g. -vvv => 3).
+  
+  All other fields take the value of the last occurrence of the option.
+  If the option's Value (see Option) can be assigned to the field, Value is
+  stored. Otherwise the option's Arg is converted to the field's type.
+*/
+func FillStruct(config interface{}, options []*Option) error {
+  fields, err := structFields(config)
+  if err != nil { return err }
+  
+  v := reflect.ValueOf(config).Elem()
+  for id, field := range fields {
+    if id >= len(options) || options[id] == nil { continue }
+    
+    fv := v.FieldByIndex(field.Index)
+    switch {
+      case fv.Kind() == reflect.Bool:
+        if b, ok := options[id].Last().Value.(bool); ok {
+          fv.SetBool(b)
+        } else {
+          fv.SetBool(true)
+        }
+        
+      case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8:
+        slice := reflect.MakeSlice(fv.Type(), 0, options[id].Count())
+        for opt := options[id]; opt != nil; opt = opt.Next() {
+          elem := reflect.New(fv.Type().Elem()).Elem()
+          if err := setField(elem, opt); err != nil { return err }
+          slice = reflect.Append(slice, elem)
+        }
+        fv.Set(slice)
+        
+      case isIntKind(fv.Kind()) && !options[id].Last().HasArg && options[id].Last().Value == nil:
+        fv.SetInt(int64(options[id].Count()))
+        
+      default:
+        if err := setField(fv, options[id].Last()); err != nil { return err }
+    }
+  }
+  
+  return nil
+}
+
+/*
+  Convenience function that calls StructUsage(), Parse() and FillStruct().
+  The "gnu" flag is implied if flags is "". Unknown options cause an error.
+*/
+func ParseStruct(args []string, config interface{}, flags string) (nonoptions []string, err error) {
+  usage, err := StructUsage(config)
+  if err != nil { return nil, err }
+  usage = append(Usage{{-1, 0, "", "", ArgUnknown, ""}}, usage...)
+  if flags == "" { flags = "gnu" }
+  options, nonoptions, err, _ := Parse(args, usage, flags)
+  if err != nil { return nil, err }
+  return nonoptions, FillStruct(config, options)
+}
+
+// Returns the fields of the struct ptr points to that have a "short" or
+// "long" tag. The index in the returned slice is the field's option Id.
+func structFields(ptr interface{}) ([]reflect.StructField, error) {
+  v := reflect.ValueOf(ptr)
+  if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+    return nil, fmt.Errorf("Expected pointer to struct but got %T", ptr)
+  }
+  
+  t := v.Elem().Type()
+  fields := []reflect.StructField{}
+  for i := 0; i < t.NumField(); i++ {
+    field := t.Field(i)
+    if field.PkgPath != "" { continue } // unexported
+    if field.Tag.Get("short") == "" && field.Tag.Get("long") == "" { continue }
+    fields = append(fields, field)
+  }
+  return fields, nil
+}
+
+// Returns the ArgChecker to use for a field of type t without "checker" tag.
+func defaultChecker(t reflect.Type) ArgChecker {
+  if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 { t = t.Elem() }
+  switch {
+    case t == reflect.TypeOf(time.Duration(0)): return ArgDuration
+    case t == reflect.TypeOf((*regexp.Regexp)(nil)): return ArgRegexp
+    case t.Kind() == reflect.Bool: return ArgNone
+    case t.Kind() == reflect.Int: return ArgInt
+    case t.Kind() == reflect.Float64: return ArgFloat
+  }
+  return ArgRequired
+}
+
+func isIntKind(k reflect.Kind) bool {
+  return k == reflect.Int || k == reflect.Int8 || k == reflect.Int16 || k == reflect.Int32 || k == reflect.Int64
+}
+
+// Stores opt's Value or Arg into fv.
+func setField(fv reflect.Value, opt *Option) error {
+  if opt.Value != nil {
+    val := reflect.ValueOf(opt.Value)
+    if val.Type().AssignableTo(fv.Type()) {
+      fv.Set(val)
+      return nil
+    }
+    if val.Type().ConvertibleTo(fv.Type()) && val.Kind() != reflect.String && fv.Kind() != reflect.String {
+      fv.Set(val.Convert(fv.Type()))
+      return nil
+    }
+  }
+  
+  var err error
+  switch fv.Kind() {
+    case reflect.Bool:
+      fv.SetBool(true)
+    case reflect.String:
+      fv.SetString(opt.Arg)
+    case reflect.Slice:
+      if fv.Type().Elem().Kind() != reflect.Uint8 {
+        return fmt.Errorf("Option '%v' cannot be stored in a field of type %v", opt, fv.Type())
+      }
+      fv.SetBytes([]byte(opt.Arg))
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+      var i int64
+      if i, err = strconv.ParseInt(opt.Arg, 10, fv.Type().Bits()); err == nil {
+        fv.SetInt(i)
+      }
+    case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+      var u uint64
+      if u, err = strconv.ParseUint(opt.Arg, 10, fv.Type().Bits()); err == nil {
+        fv.SetUint(u)
+      }
+    case reflect.Float32, reflect.Float64:
+      var f float64
+      if f, err = strconv.ParseFloat(opt.Arg, fv.Type().Bits()); err == nil {
+        fv.SetFloat(f)
+      }
+    default:
+      return fmt.Errorf("Option '%v' cannot be stored in a field of type %v", opt, fv.Type())
+  }
+  
+  if err != nil {
+    return fmt.Errorf("Option '%v' requires an argument of type %v", opt, fv.Type())
+  }
+  return nil
+}
@@ -0,0 +1,217 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named checkers.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package argv
+
+import (
+         "os"
+         "fmt"
+         "time"
+         "regexp"
+         "strings"
+         "strconv"
+       )
+
+/*
+  ArgChecker that accepts floating point numbers and stores them as float64 in option.Value.
+*/
+func ArgFloat(option *Option) error {
+  if option.HasArg {
+    f, err := strconv.ParseFloat(option.Arg, 64)
+    if err == nil {
+      option.Value = f
+      return ARG_OK
+    }
+  }
+  return fmt.Errorf("Option '%v' requires a number as argument", option)
+}
+
+/*
+  ArgChecker that accepts durations such as "1h30m" or "250ms" (see time.ParseDuration())
+  and stores them as time.Duration in option.Value.
+*/
+func ArgDuration(option *Option) error {
+  if option.HasArg {
+    d, err := time.ParseDuration(option.Arg)
+    if err == nil {
+      option.Value = d
+      return ARG_OK
+    }
+  }
+  return fmt.Errorf("Option '%v' requires a duration (e.g. 1h30m, 10s, 250ms) as argument", option)
+}
+
+/*
+  ArgChecker that accepts boolean values and stores them as bool in option.Value.
+  In addition to the values accepted by strconv.ParseBool() it accepts
+  "yes", "no", "on" and "off" (in any case).
+*/
+func ArgBool(option *Option) error {
+  if s, ok := option.Value.(*Suggestions); ok {
+    s.Words = []string{"true", "false"}
+  }
+  if option.HasArg {
+    switch strings.ToLower(option.Arg) {
+      case "yes", "on": option.Value = true; return ARG_OK
+      case "no", "off": option.Value = false; return ARG_OK
+    }
+    b, err := strconv.ParseBool(option.Arg)
+    if err == nil {
+      option.Value = b
+      return ARG_OK
+    }
+  }
+  return fmt.Errorf("Option '%v' requires a boolean (true or false) as argument", option)
+}
+
+/*
+  Returns an ArgChecker that accepts only the given choices (case-sensitive)
+  and stores the argument as string in option.Value.
+*/
+func ArgEnum(choices ...string) ArgChecker {
+  return func(option *Option) error {
+    if s, ok := option.Value.(*Suggestions); ok {
+      s.Words = choices
+    }
+    if option.HasArg {
+      for _, choice := range choices {
+        if option.Arg == choice {
+          option.Value = choice
+          return ARG_OK
+        }
+      }
+    }
+    return fmt.Errorf("Option '%v' requires one of the following as argument: %v", option, strings.Join(choices, ", "))
+  }
+}
+
+/*
+  Returns an ArgChecker that accepts base-10 integers in the range min..max
+  (including both) and stores them as int in option.Value.
+*/
+func ArgIntRange(min, max int) ArgChecker {
+  return func(option *Option) error {
+    if option.HasArg {
+      i, err := strconv.Atoi(option.Arg)
+      if err == nil && i >= min && i <= max {
+        option.Value = i
+        return ARG_OK
+      }
+    }
+    return fmt.Errorf("Option '%v' requires an integer in the range %v..%v as argument", option, min, max)
+  }
+}
+
+/*
+  ArgChecker that accepts sizes in bytes with an optional suffix k, M, G or T
+  (case-insensitive, optionally followed by "B" or "iB") which multiplies the
+  number by 1024, 1024², 1024³ or 1024⁴ respectively. E.g. "512", "64k", "1.5G", "2MiB".
+  The size is stored as int64 in option.Value.
+*/
+func ArgSize(option *Option) error {
+  if option.HasArg {
+    num := option.Arg
+    if strings.HasSuffix(num, "iB") {
+      num = num[:len(num)-2]
+    } else {
+      num = strings.TrimSuffix(num, "B")
+    }
+    factor := int64(1)
+    if num != "" {
+      switch num[len(num)-1] {
+        case 'k', 'K': factor = 1 << 10
+        case 'm', 'M': factor = 1 << 20
+        case 'g', 'G': factor = 1 << 30
+        case 't', 'T': factor = 1 << 40
+      }
+      if factor != 1 { num = num[:len(num)-1] }
+    }
+    
+    if i, err := strconv.ParseInt(num, 10, 64); err == nil {
+      if i >= 0 && i <= (1<<63-1)/factor {
+        option.Value = i * factor
+        return ARG_OK
+      }
+    } else if f, err := strconv.ParseFloat(num, 64); err == nil && factor != 1 {
+      if f >= 0 && f*float64(factor) < (1<<63) {
+        option.Value = int64(f * float64(factor))
+        return ARG_OK
+      }
+    }
+  }
+  return fmt.Errorf("Option '%v' requires a size (e.g. 512, 64k, 1.5G) as argument", option)
+}
+
+/*
+  ArgChecker that accepts the name of an existing file (or directory) and stores
+  its os.FileInfo in option.Value.
+*/
+func ArgExistingFile(option *Option) error {
+  if s, ok := option.Value.(*Suggestions); ok {
+    s.Files = true
+  }
+  if option.HasArg && option.Arg != "" {
+    fi, err := os.Stat(option.Arg)
+    if err == nil {
+      option.Value = fi
+      return ARG_OK
+    }
+    return fmt.Errorf("Option '%v': %v", option, err)
+  }
+  return fmt.Errorf("Option '%v' requires the name of an existing file as argument", option)
+}
+
+/*
+  ArgChecker that accepts regular expressions (see package regexp) and stores
+  the compiled *regexp.Regexp in option.Value.
+*/
+func ArgRegexp(option *Option) error {
+  if option.HasArg {
+    re, err := regexp.Compile(option.Arg)
+    if err == nil {
+      option.Value = re
+      return ARG_OK
+    }
+    return fmt.Errorf("Option '%v': %v", option, err)
+  }
+  return fmt.Errorf("Option '%v' requires a regular expression as argument", option)
+}
+
+// KeyValue stores the result of parsing "key=value" in option.Value (see ArgKeyValue).
+type KeyValue struct {
+  Key string
+  Value string
+}
+
+/*
+  ArgChecker that accepts arguments of the form "key=value" with a non-empty key
+  and stores them as KeyValue in option.Value. The value may be empty and may
+  contain '=' (only the first '=' separates key and value).
+*/
+func ArgKeyValue(option *Option) error {
+  if option.HasArg {
+    if eq := strings.Index(option.Arg, "="); eq > 0 {
+      option.Value = KeyValue{option.Arg[:eq], option.Arg[eq+1:]}
+      return ARG_OK
+    }
+  }
+  return fmt.Errorf("Option '%v' requires an argument of the form key=value", option)
+}
@@ -0,0 +1,196 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named commands.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package argv
+
+import (
+         "fmt"
+       )
+
+/*
+  Describes a (sub-)command for programs with a git-style command line
+  such as "tool remote add --force x". Commands form a tree. The root
+  of the tree describes the program itself and its global options, the
+  children describe the words that may follow.
+  
+  E.g.
+  
+    var add = &argv.Command{Name:"add", Usage:addUsage, Help:"Add a remote."}
+    var remote = &argv.Command{Name:"remote", Usage:remoteUsage, 
+                               Help:"Manage remotes.", Commands:[]*argv.Command{add}}
+    var tool = &argv.Command{Name:"tool", Usage:globalUsage, Commands:[]*argv.Command{remote}}
+*/
+type Command struct {
+  /*
+    The word in the argument vector that selects this command. For the root
+    command this is only used for display purposes (typically the program name).
+  */
+  Name string
+  
+  /*
+    The options accepted after Name (and before the word selecting a sub-command,
+    if any). Ids only need to be unique within the same Usage, because
+    ParseCommands() returns a separate options slice for each command.
+  */
+  Usage Usage
+  
+  /*
+    The flags passed to Parse() for this command's options (see Parse()).
+    If Flags is "", the flags passed to ParseCommands() are used.
+  */
+  Flags string
+  
+  /*
+    Short description of the command for the command list produced by
+    CommandTable(). Formatting characters such as '\t' and '\v' should
+    not be used, but '\n' may be used to add additional rows.
+  */
+  Help string
+  
+  /*
+    The sub-commands that may follow this command's options.
+  */
+  Commands []*Command
+}
+
+/*
+  Returns the sub-command of c whose Name is name or nil if none exists.
+*/
+func (c *Command) Find(name string) *Command {
+  for _, sub := range c.Commands {
+    if sub.Name == name { return sub }
+  }
+  return nil
+}
+
+/*
+  Returns a Usage whose Help texts form a table with one row per
+  sub-command of c, listing the Name in the 1st and the Help in the 2nd column.
+  The returned Usage is intended for Usage.String() and can be concatenated
+  with other Usages (e.g. the command's own options), but it contains only
+  dummy OptionInfos that do not describe any options.
+*/
+func (c *Command) CommandTable() Usage {
+  table := Usage{}
+  for _, sub := range c.Commands {
+    table = append(table, OptionInfo{-1, 0, "", "", ArgUnknown, "  " + sub.Name + "  \t" + sub.Help})
+  }
+  return table
+}
+
+/*
+  Returns the help for c, consisting of the Help texts of c.Usage followed
+  (as a separate table) by the CommandTable() if c has sub-commands.
+  See Usage.String() for details on the formatting.
+*/
+func (c *Command) String() string {
+  usage := append(Usage{}, c.Usage...)
+  if len(c.Commands) > 0 {
+    usage = append(usage, OptionInfo{-1, 0, "", "", ArgUnknown, "\f"})
+    usage = append(usage, c.CommandTable()...)
+  }
+  return defaultFormatter().Format(usage)
+}
+
+/*
+  Parses an argument vector (typically os.Args[1:]) for a tree of commands
+  rooted at root.
+  The options at the beginning of args are parsed with root.Usage. The first
+  non-option word is compared to the Names of root's sub-commands. If it matches
+  one, parsing of root's options stops there and the words following the
+  sub-command's name are parsed with that sub-command's Usage, and so on.
+  If the first non-option word does not match a sub-command, it is treated as an
+  ordinary non-option and the rest of the argument vector is parsed as described
+  for Parse() (i.e. in "gnu" mode, further options may follow).
+  
+  flags are the flags for Parse() and are used for every command whose Flags field
+  is "". Response files (the "@file" flag) are expanded only if flags contains
+  "@file" and they are expanded for the complete argument vector before
+  parsing starts, so they may contain command words.
+  
+  The returned values are
+  
+    path: the chosen commands. path[0] is always root, path[len(path)-1] is the
+          command selected by the last command word in the argument vector.
+          
+    options: options[i] is the options slice returned by Parse() for path[i].
+    
+    nonoptions: the non-option words following the last command (see Parse()).
+                Non-options can only appear after the last command, because the
+                first non-option word either is a command or ends the search for
+                further commands.
+                
+    err: if non-nil something went wrong and the other return values have
+         unspecified values.
+         
+    alloptions: alloptions[i] is the alloptions slice returned by Parse() for path[i].
+*/
+func ParseCommands(args []string, root *Command, flags string) (path []*Command, options [][]*Option, nonoptions []string, err error, alloptions [][]*Option) {
+  var fl parseflags
+  fl, err = parseFlags(flags)
+  if err != nil { return }
+  
+  var origins []wordorigin
+  if fl.response_files {
+    args, origins, err = expandResponseFiles(args)
+    if err != nil { return }
+  }
+  
+  cmd := root
+  offset := 0 // index of args[0] within the (expanded) argument vector
+  for {
+    path = append(path, cmd)
+    
+    cmdflags := cmd.Flags
+    if cmdflags == "" { cmdflags = flags }
+    
+    var is_command func(string) bool
+    if len(cmd.Commands) > 0 {
+      is_command = func(word string) bool { return cmd.Find(word) != nil }
+    }
+    
+    opts, nonopts, e, allopts, rest := parse(args, cmd.Usage, cmdflags, is_command)
+    if e != nil {
+      if perr, ok := e.(*ParseError); ok && perr.Index >= 0 {
+        perr.Index += offset // make Index relative to the complete argument vector
+      }
+      err = relocateError(e, origins)
+      return
+    }
+    
+    options = append(options, opts)
+    alloptions = append(alloptions, allopts)
+    
+    if rest == nil {
+      nonoptions = nonopts
+      for _, allopts := range alloptions { warnDeprecated(allopts) }
+      return
+    }
+    
+    cmd = cmd.Find(rest[0])
+    if cmd == nil { // can't happen because is_command() returned true
+      err = fmt.Errorf("Unknown command '%v'", rest[0])
+      return
+    }
+    offset += len(args) - len(rest) + 1
+    args = rest[1:]
+  }
+}
@@ -0,0 +1,332 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named completion.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package argv
+
+import (
+         "fmt"
+         "strings"
+       )
+
+/*
+  When CompletionScript() generates a completion script, it calls the ArgChecker
+  of every option with an Option whose Value is a *Suggestions. HasArg is true,
+  Arg is "" and ArgAttached is false. If the ArgChecker returns an ARG_NONE error,
+  it is called a second time with ArgAttached==true. This determines whether
+  the option takes an argument (see ArgChecker).
+  An ArgChecker that knows what arguments are acceptable can describe them by
+  filling in the *Suggestions. E.g.
+  
+    func ArgColor(option *argv.Option) error {
+      if s, ok := option.Value.(*argv.Suggestions); ok {
+        s.Words = []string{"red", "green", "blue"}
+      }
+      ...
+    }
+  
+  If none of the fields is set, completion scripts complete file names, because
+  that is the default behaviour of shells.
+*/
+type Suggestions struct {
+  // The complete list of acceptable arguments. Words should not contain spaces.
+  Words []string
+  
+  // The argument is a file name.
+  Files bool
+  
+  // The argument is a directory name.
+  Dirs bool
+}
+
+// Information about one option (i.e. one OptionInfo) for generating completion scripts.
+type completion struct {
+  shorts []string // e.g. "-p"
+  longs []string  // e.g. "--port"
+  perls []string  // e.g. "-port" (only with the "-perl" flag)
+  abbrs []string  // unambiguous abbreviations of longs (only with "--abb..." flag)
+  arg int         // 0: no argument, 1: required argument, 2: optional attached argument
+  sugg Suggestions
+  desc string
+}
+
+/*
+  Generates a script for the given shell ("bash", "zsh" or "fish") that
+  provides command line completion of the options in usage for the program
+  named program.
+  flags are the same flags you pass to Parse(). They determine
+  if single-minus long options ("-perl") and abbreviations ("--abb...")
+  are completed and (for zsh) if options may follow non-options ("gnu").
+  See Suggestions for how ArgCheckers can provide possible argument values.
+  
+  The description shown by shells that support it (zsh and fish) is the last
+  cell in the first row of the respective OptionInfo.Help.
+  
+  The following commands install the scripts for the respective shells:
+  
+    bash: program --completion=bash >/etc/bash_completion.d/program
+    zsh:  program --completion=zsh  >"${fpath[1]}/_program"
+    fish: program --completion=fish >~/.config/fish/completions/program.fish
+  
+  (assuming program has an option --completion that prints the result of
+  this function).
+*/
+func (usage Usage) CompletionScript(shell string, program string, flags string) (string, error) {
+  fl, err := parseFlags(flags)
+  if err != nil { return "", err }
+  
+  comps := usage.completions(fl)
+  switch shell {
+    case "bash": return bashCompletion(comps, program), nil
+    case "zsh":  return zshCompletion(comps, program, fl), nil
+    case "fish": return fishCompletion(comps, program), nil
+  }
+  return "", fmt.Errorf("CompletionScript(): Unsupported shell: %v", shell)
+}
+
+// Collects the completion information for all options in usage.
+func (usage Usage) completions(fl parseflags) []completion {
+  comps := []completion{}
+  for i := range usage {
+    info := &usage[i]
+    if info.Short == "" && info.Long == "" { continue }
+    if info.Meta().Hidden { continue }
+    
+    var c completion
+    for k := 0; k < len(info.Short); k++ {
+      c.shorts = append(c.shorts, "-" + info.Short[k:k+1])
+    }
+    if info.Long != "" {
+      c.longs = append(c.longs, "--" + info.Long)
+      if fl.single_minus_longopt {
+        c.perls = append(c.perls, "-" + info.Long)
+      }
+      if fl.min_abbr_len > 0 {
+        for l := fl.min_abbr_len; l < len(info.Long); l++ {
+          if usage.uniquePrefix(info.Long[0:l]) {
+            c.abbrs = append(c.abbrs, "--" + info.Long[0:l])
+            if fl.single_minus_longopt {
+              c.abbrs = append(c.abbrs, "-" + info.Long[0:l])
+            }
+          }
+        }
+      }
+    }
+    
+    opt := &Option{Name: "", HasArg: true, Info: info, Value: &c.sugg}
+    if _, ok := info.CheckArg(opt).(ARG_NONE); !ok {
+      c.arg = 1
+    } else {
+      opt = &Option{Name: "", HasArg: true, ArgAttached: true, Info: info, Value: &c.sugg}
+      if _, ok := info.CheckArg(opt).(ARG_NONE); !ok {
+        c.arg = 2
+      }
+    }
+    
+    c.desc = helpSummary(info.Help)
+    comps = append(comps, c)
+  }
+  return comps
+}
+
+// Returns true iff exactly one distinct long option name in usage starts with prefix.
+func (usage Usage) uniquePrefix(prefix string) bool {
+  match := ""
+  for i := range usage {
+    if strings.HasPrefix(usage[i].Long, prefix) {
+      if match != "" && match != usage[i].Long { return false }
+      match = usage[i].Long
+    }
+  }
+  return match != ""
+}
+
+/*
+  Returns the last cell of the first row of help with '\v' replaced by ' '.
+  Returns "" if the first row has only one cell (i.e. it's a plain line insertion).
+*/
+func helpSummary(help string) string {
+  if nl := strings.IndexByte(help, '\n'); nl >= 0 { help = help[0:nl] }
+  tab := strings.LastIndexByte(help, '\t')
+  if tab < 0 { return "" }
+  return strings.Join(strings.Fields(strings.Replace(help[tab+1:], "\v", " ", -1)), " ")
+}
+
+// Returns s in single quotes, suitable for bash and zsh.
+func shellQuote(s string) string {
+  return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// Returns program with all characters that are not allowed in shell function names replaced by '_'.
+func shellIdentifier(program string) string {
+  return strings.Map(func(r rune) rune {
+    if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') { return r }
+    return '_'
+  }, program)
+}
+
+func bashCompletion(comps []completion, program string) string {
+  fn := "_argv_" + shellIdentifier(program)
+  var script []string
+  script = append(script, 
+    "# bash completion for " + program + " generated by argv.Usage.CompletionScript()",
+    fn + "()",
+    "{",
+    "  local cur prev",
+    "  cur=\"${COMP_WORDS[COMP_CWORD]}\"",
+    "  prev=\"${COMP_WORDS[COMP_CWORD-1]}\"",
+    "  # bash splits --foo=bar into 3 words \"--foo\" \"=\" \"bar\"",
+    "  if [[ \"$cur\" == \"=\" ]]; then",
+    "    cur=\"\"",
+    "    prev=\"$prev=\"",
+    "  elif [[ \"$prev\" == \"=\" ]]; then",
+    "    prev=\"${COMP_WORDS[COMP_CWORD-2]}=\"",
+    "  fi",
+    "  case \"$prev\" in")
+  
+  names := []string{}
+  for _, c := range comps {
+    names = append(names, c.shorts...)
+    names = append(names, c.longs...)
+    names = append(names, c.perls...)
+    
+    if c.arg == 0 { continue }
+    
+    patterns := []string{}
+    for _, l := range [][]string{c.longs, c.perls, c.abbrs} {
+      for _, name := range l {
+        patterns = append(patterns, name + "=")
+        if c.arg == 1 { patterns = append(patterns, name) }
+      }
+    }
+    if c.arg == 1 { patterns = append(patterns, c.shorts...) }
+    if len(patterns) == 0 { continue }
+    
+    action := "compgen -f -- \"$cur\""
+    if len(c.sugg.Words) > 0 {
+      action = "compgen -W " + shellQuote(strings.Join(c.sugg.Words, " ")) + " -- \"$cur\""
+    } else if c.sugg.Dirs {
+      action = "compgen -d -- \"$cur\""
+    }
+    
+    script = append(script, 
+      "    " + strings.Join(patterns, "|") + ")",
+      "      COMPREPLY=( $(" + action + ") )",
+      "      return 0;;")
+  }
+  
+  script = append(script,
+    "  esac",
+    "  if [[ \"$cur\" == -* ]]; then",
+    "    COMPREPLY=( $(compgen -W " + shellQuote(strings.Join(names, " ")) + " -- \"$cur\") )",
+    "    return 0",
+    "  fi",
+    "  COMPREPLY=( $(compgen -f -- \"$cur\") )",
+    "}",
+    "complete -F " + fn + " " + program,
+    "")
+  return strings.Join(script, "\n")
+}
+
+// Returns s with the characters in special escaped by backslash.
+func backslashEscape(s string, special string) string {
+  var out []byte
+  for i := 0; i < len(s); i++ {
+    if s[i] == '\\' || strings.IndexByte(special, s[i]) >= 0 { out = append(out, '\\') }
+    out = append(out, s[i])
+  }
+  return string(out)
+}
+
+func zshCompletion(comps []completion, program string, fl parseflags) string {
+  var script []string
+  script = append(script,
+    "#compdef " + program,
+    "# zsh completion for " + program + " generated by argv.Usage.CompletionScript()",
+    "")
+  
+  args := "_arguments -s -S"
+  if !fl.gnu { args += " -A '-*'" }
+  script = append(script, args + " \\")
+  
+  for _, c := range comps {
+    action := "_files"
+    if len(c.sugg.Words) > 0 {
+      words := []string{}
+      for _, w := range c.sugg.Words { words = append(words, backslashEscape(w, " ()")) }
+      action = "(" + strings.Join(words, " ") + ")"
+    } else if c.sugg.Dirs {
+      action = "_files -/"
+    }
+    
+    desc := "[" + backslashEscape(c.desc, "[]") + "]"
+    for _, l := range [][]string{c.shorts, c.longs, c.perls} {
+      for _, name := range l {
+        spec := "*" + name
+        short := len(name) == 2 && name[1] != '-'
+        switch c.arg {
+          case 0: spec += desc
+          case 1: 
+            if short { spec += "+" } else { spec += "=" }
+            spec += desc + ":arg:" + action
+          case 2:
+            if short { spec += "-" } else { spec += "=-" }
+            spec += desc + "::arg:" + action
+        }
+        script = append(script, "  " + shellQuote(spec) + " \\")
+      }
+    }
+  }
+  
+  script = append(script, "  '*:file:_files'", "")
+  return strings.Join(script, "\n")
+}
+
+// Returns s in single quotes, suitable for fish.
+func fishQuote(s string) string {
+  return "'" + strings.Replace(strings.Replace(s, `\`, `\\`, -1), "'", `\'`, -1) + "'"
+}
+
+func fishCompletion(comps []completion, program string) string {
+  var script []string
+  script = append(script, "# fish completion for " + program + " generated by argv.Usage.CompletionScript()")
+  
+  for _, c := range comps {
+    line := "complete -c " + fishQuote(program)
+    for _, name := range c.shorts { line += " -s " + fishQuote(name[1:]) }
+    for _, name := range c.longs { line += " -l " + fishQuote(name[2:]) }
+    for _, name := range c.perls { line += " -o " + fishQuote(name[1:]) }
+    if c.desc != "" { line += " -d " + fishQuote(c.desc) }
+    
+    if c.arg == 1 {
+      if len(c.sugg.Words) > 0 {
+        line += " -x -a " + fishQuote(strings.Join(c.sugg.Words, " "))
+      } else if c.sugg.Dirs {
+        line += " -x -a '(__fish_complete_directories)'"
+      } else {
+        line += " -r -F"
+      }
+    }
+    script = append(script, line)
+  }
+  
+  script = append(script, "")
+  return strings.Join(script, "\n")
+}
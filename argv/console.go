@@ -0,0 +1,217 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named console.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+
+package argv
+
+import (
+         "io"
+         "fmt"
+         "errors"
+         "strings"
+       )
+
+/*
+  A command understood by a Console, e.g. "set" in "set --timeout=5 foo".
+  The Console uses the Name, Usage, Flags and Help of the embedded Command.
+  Its Commands are not used, i.e. console commands have no sub-commands.
+*/
+type ConsoleCommand struct {
+  Command
+  
+  /*
+    Called with the result of Parse() for a line that selects this command
+    (without the command word itself). Output should be written to out.
+    If Handler returns an error, the Console prints it. If it returns
+    ConsoleQuit, Console.Run() returns. If Handler is nil, the command does nothing.
+  */
+  Handler func(out io.Writer, options []*Option, nonoptions []string) error
+}
+
+// Returned by a ConsoleCommand.Handler to make Console.Run() return.
+var ConsoleQuit = errors.New("Quit")
+
+/*
+  A line-oriented command interpreter, e.g. for an admin console, that
+  dispatches each line to the ConsoleCommand selected by its first word.
+  
+  A line is split into words with SplitCommandLine() (see there for the quoting
+  rules), the words following the command word are parsed with Parse() using
+  the command's Usage and Flags and the result is passed to the command's Handler.
+  Empty lines and comments are ignored. If Parse() fails, the error is printed
+  followed by the command's Usage. If the command word is unknown, the
+  error is printed followed by the list of commands.
+  
+  Unless one of the Commands is called "help", the Console provides a built-in
+  "help" command that prints the list of commands or (with a command name as
+  argument) the Usage of that command.
+*/
+type Console struct {
+  // The commands understood by the Console.
+  Commands []*ConsoleCommand
+  
+  // Written to the output before each line is read by Run(). May be "".
+  Prompt string
+  
+  // Passed to SplitCommandLine() for $VAR expansion. If nil, '$' has no special meaning.
+  Expand func(name string) string
+  
+  /*
+    Used to format the Usage of commands and the list of commands.
+    If nil, Usage.String() is used, i.e. the width of os.Stdout, which is probably
+    not what you want if the console's output is not os.Stdout.
+  */
+  Formatter *Formatter
+}
+
+// Returns the command named name or nil if none exists.
+func (c *Console) Find(name string) *ConsoleCommand {
+  for _, cmd := range c.Commands {
+    if cmd.Name == name { return cmd }
+  }
+  return nil
+}
+
+/*
+  Reads lines from in and Execute()s them, writing all output to out,
+  until in returns an error or a Handler returns ConsoleQuit.
+  A final line without '\n' is executed, too. Returns nil if
+  in returned io.EOF or a Handler returned ConsoleQuit. Otherwise
+  returns the error from in. Errors from commands do not stop Run().
+  
+  Unless in implements io.ByteReader, Run() reads single bytes, so that
+  no data following the last line read is consumed from in.
+  This makes it possible to run a Console on a network connection for
+  a while and then continue using the connection for other purposes.
+  If you need timeouts, read the lines yourself (e.g. with util.ReadLn())
+  and pass them to Execute().
+*/
+func (c *Console) Run(in io.Reader, out io.Writer) error {
+  br, ok := in.(io.ByteReader)
+  if !ok { br = &bytereader{r:in} }
+  for {
+    if c.Prompt != "" { io.WriteString(out, c.Prompt) }
+    line, err := readLine(br)
+    if err == nil || (err == io.EOF && line != "") {
+      if c.Execute(line, out) == ConsoleQuit { return nil }
+    }
+    if err == io.EOF { return nil }
+    if err != nil { return err }
+  }
+}
+
+/*
+  Executes a single line as described for Console and returns the error
+  printed to out (if any) or the error returned by the Handler (including
+  ConsoleQuit, which is not printed).
+*/
+func (c *Console) Execute(line string, out io.Writer) error {
+  words, err := SplitCommandLine(line, c.Expand)
+  if err != nil {
+    fmt.Fprintf(out, "%v\n", err)
+    return err
+  }
+  if len(words) == 0 { return nil }
+  
+  cmd := c.Find(words[0])
+  if cmd == nil {
+    if words[0] == "help" { return c.help(words[1:], out) }
+    err = fmt.Errorf("Unknown command '%v'%v", words[0], c.suggest(words[0]))
+    fmt.Fprintf(out, "%v\n%v", err, c.format(c.commandTable()))
+    return err
+  }
+  
+  options, nonoptions, err, _ := Parse(words[1:], cmd.Usage, cmd.Flags)
+  if err != nil {
+    fmt.Fprintf(out, "%v\n%v", err, c.format(cmd.Usage))
+    return err
+  }
+  
+  if cmd.Handler == nil { return nil }
+  err = cmd.Handler(out, options, nonoptions)
+  if err != nil && err != ConsoleQuit {
+    fmt.Fprintf(out, "%v\n", err)
+  }
+  return err
+}
+
+// Implements the built-in "help" command.
+func (c *Console) help(args []string, out io.Writer) error {
+  if len(args) == 0 {
+    io.WriteString(out, c.format(c.commandTable()))
+    return nil
+  }
+  cmd := c.Find(args[0])
+  if cmd == nil {
+    err := fmt.Errorf("Unknown command '%v'%v", args[0], c.suggest(args[0]))
+    fmt.Fprintf(out, "%v\n", err)
+    return err
+  }
+  io.WriteString(out, c.format(cmd.Usage))
+  return nil
+}
+
+// Returns a Usage that lists the commands and their Help.
+func (c *Console) commandTable() Usage {
+  list := Command{}
+  for _, cmd := range c.Commands { list.Commands = append(list.Commands, &cmd.Command) }
+  if c.Find("help") == nil {
+    list.Commands = append(list.Commands, &Command{Name: "help [<command>]", Help: "List commands or show help for <command>."})
+  }
+  return append(Usage{{-1, 0, "", "", ArgUnknown, "Commands:"}}, list.CommandTable()...)
+}
+
+func (c *Console) format(usage Usage) string {
+  if c.Formatter == nil { return usage.String() }
+  return c.Formatter.Format(usage)
+}
+
+// Returns ". Did you mean X?" if there are commands similar to name, otherwise "".
+func (c *Console) suggest(name string) string {
+  names := []string{}
+  for _, cmd := range c.Commands { names = append(names, cmd.Name) }
+  return didYouMean(similarNames(name, names))
+}
+
+// Reads up to and including the next '\n'. Returns the line without trailing "\n" or "\r\n".
+func readLine(br io.ByteReader) (string, error) {
+  var line []byte
+  for {
+    b, err := br.ReadByte()
+    if err != nil { return strings.TrimRight(string(line), "\r"), err }
+    if b == '\n' { return strings.TrimRight(string(line), "\r"), nil }
+    line = append(line, b)
+  }
+}
+
+// An io.ByteReader that reads single bytes from r.
+type bytereader struct {
+  r io.Reader
+  buf [1]byte
+}
+
+func (b *bytereader) ReadByte() (byte, error) {
+  for {
+    n, err := b.r.Read(b.buf[:])
+    if n == 1 { return b.buf[0], nil }
+    if err != nil { return 0, err }
+  }
+}
@@ -0,0 +1,168 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named constraints.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package argv
+
+import (
+         "fmt"
+         "strings"
+       )
+
+// Kinds of Constraint.
+const (
+  // All of Constraint.Ids must be present.
+  REQUIRED = iota
+  // At most one of Constraint.Ids may be present.
+  EXCLUSIVE
+  // If Constraint.Id is present, all of Constraint.Ids must be present.
+  REQUIRES
+)
+
+/*
+  Describes a relation between options that CheckConstraints() verifies.
+  Options are identified by their OptionInfo.Id, so all options with the same
+  Id (e.g. --enable-foo and --disable-foo) are treated alike.
+  Use the functions Required(), Exclusive() and Requires() to create Constraints.
+*/
+type Constraint struct {
+  Kind int
+  Id int
+  Ids []int
+}
+
+// Returns a Constraint that fails if any of the options with the given ids is missing.
+func Required(ids ...int) Constraint { return Constraint{REQUIRED, -1, ids} }
+
+// Returns a Constraint that fails if more than one of the options with the given ids is present.
+func Exclusive(ids ...int) Constraint { return Constraint{EXCLUSIVE, -1, ids} }
+
+// Returns a Constraint that fails if the option with the given id is present
+// but any of the options with the ids in needed is missing.
+func Requires(id int, needed ...int) Constraint { return Constraint{REQUIRES, id, needed} }
+
+/*
+  A violation of a Constraint found by CheckConstraints().
+*/
+type Violation struct {
+  // The Constraint that is violated.
+  Constraint Constraint
+  
+  /*
+    The names of the offending options. For options present in the argument
+    vector this is the Option.String() of the first occurrence, which includes
+    the Option.Source if the option was not taken from the argument vector
+    itself (e.g. "--verbose (from $VERBOSE)"). For missing options
+    it's the name from the Usage ("--long" if available, otherwise "-s").
+    
+      REQUIRED: the names of the missing options.
+      EXCLUSIVE: the names of the options present.
+      REQUIRES: the name of Constraint.Id's option followed by the names
+                of the missing options.
+  */
+  Names []string
+}
+
+func (v *Violation) Error() string {
+  names := "'" + strings.Join(v.Names, "', '") + "'"
+  switch v.Constraint.Kind {
+    case REQUIRED:
+      if len(v.Names) == 1 { return fmt.Sprintf("Option %v is required", names) }
+      return fmt.Sprintf("Options %v are required", names)
+    case EXCLUSIVE:
+      return fmt.Sprintf("Options %v are mutually exclusive", names)
+    case REQUIRES:
+      return fmt.Sprintf("Option '%v' requires '%v'", v.Names[0], strings.Join(v.Names[1:], "', '"))
+  }
+  return fmt.Sprintf("Unknown constraint violated by %v", names)
+}
+
+/*
+  The error returned by CheckConstraints(). It contains all violations
+  in the order of the constraints passed to CheckConstraints().
+*/
+type ConstraintError []*Violation
+
+func (e ConstraintError) Error() string {
+  msgs := make([]string, len(e))
+  for i, v := range e { msgs[i] = v.Error() }
+  return strings.Join(msgs, "\n")
+}
+
+/*
+  Checks options (as returned by Parse() for usage) against all constraints.
+  Returns nil if all constraints are satisfied. Otherwise returns a ConstraintError
+  with one Violation per violated Constraint. E.g.
+  
+    options, nonoptions, err, _ := argv.Parse(os.Args[1:], usage, "gnu")
+    if err == nil {
+      err = argv.CheckConstraints(usage, options,
+                                  argv.Required(INPUT),
+                                  argv.Exclusive(QUIET, VERBOSE),
+                                  argv.Requires(KEY, CERT))
+    }
+*/
+func CheckConstraints(usage Usage, options []*Option, constraints ...Constraint) error {
+  var violations ConstraintError
+  
+  present := func(id int) bool { return id >= 0 && id < len(options) && options[id] != nil }
+  
+  for _, c := range constraints {
+    v := &Violation{Constraint: c}
+    switch c.Kind {
+      case REQUIRED:
+        for _, id := range c.Ids {
+          if !present(id) { v.Names = append(v.Names, usage.optionName(id)) }
+        }
+      case EXCLUSIVE:
+        for _, id := range c.Ids {
+          if present(id) { v.Names = append(v.Names, options[id].String()) }
+        }
+        if len(v.Names) < 2 { v.Names = nil }
+      case REQUIRES:
+        if present(c.Id) {
+          for _, id := range c.Ids {
+            if !present(id) { v.Names = append(v.Names, usage.optionName(id)) }
+          }
+          if v.Names != nil { v.Names = append([]string{options[c.Id].String()}, v.Names...) }
+        }
+      default:
+        return fmt.Errorf("CheckConstraints(): Unknown constraint kind: %v", c.Kind)
+    }
+    if v.Names != nil { violations = append(violations, v) }
+  }
+  
+  if violations == nil { return nil }
+  return violations
+}
+
+/*
+  Returns the name of the first option in usage with the given id, including
+  leading "-" character(s). The long name is preferred.
+*/
+func (usage Usage) optionName(id int) string {
+  for i := range usage {
+    if usage[i].Id == id {
+      if usage[i].Long != "" { return "--" + usage[i].Long }
+      if usage[i].Short != "" { return "-" + usage[i].Short[0:1] }
+    }
+  }
+  return fmt.Sprintf("#%v", id)
+}
@@ -0,0 +1,122 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named fallback.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package argv
+
+import (
+         "io"
+         "os"
+         "fmt"
+         "bufio"
+         "strings"
+         "strconv"
+       )
+
+/*
+  The configuration consulted by Parse() for options whose OptionMeta has a Key
+  that is missing from the argument vector and the environment. nil (the default)
+  means no configuration. Usually set from ReadConfig().
+*/
+var Config map[string]string
+
+/*
+  Synthesizes the options missing from options (indexed by Id) from the
+  environment and Config as described for OptionMeta.Env and OptionMeta.Key,
+  appends them to alloptions and links them into options. The new alloptions
+  is returned. Synthesized options appear in the order of usage.
+  ArgCheckers are only called for options that are actually synthesized.
+*/
+func (usage Usage) fallbacks(options []*Option, alloptions []*Option) ([]*Option, error) {
+  for i := range usage {
+    info := &usage[i]
+    if info.Id < 0 || info.Id >= len(options) || options[info.Id] != nil { continue }
+    
+    meta := info.Meta()
+    var value, source string
+    found := false
+    if meta.Env != "" {
+      value, found = os.LookupEnv(meta.Env)
+      source = "$" + meta.Env
+    }
+    if !found && meta.Key != "" && Config != nil {
+      value, found = Config[meta.Key]
+      source = "config key " + meta.Key
+    }
+    if !found { continue }
+    
+    name := ""
+    if info.Long != "" {
+      name = "--" + info.Long
+    } else if info.Short != "" {
+      name = "-" + info.Short[0:1]
+    }
+    
+    option := &Option{
+      Name: name,
+      HasArg: true,
+      Arg: value,
+      ArgAttached: true,
+      Info: info,
+      Source: source,
+    }
+    
+    err := info.CheckArg(option)
+    if _, ok := err.(ARG_NONE); ok {
+      b, e := strconv.ParseBool(value)
+      if e != nil {
+        return alloptions, &ParseError{Kind: UNEXPECTED_ARGUMENT, Index: -1, Info: info, Option: option, Err: err}
+      }
+      if !b { continue }
+      option.HasArg = false
+      option.Arg = ""
+      option.ArgAttached = false
+    } else if err != nil {
+      return alloptions, &ParseError{Kind: INVALID_ARGUMENT, Index: -1, Info: info, Option: option, Err: err}
+    }
+    
+    alloptions = append(alloptions, option)
+    link(options, option)
+  }
+  
+  return alloptions, nil
+}
+
+/*
+  Reads a simple configuration from r, suitable for Config.
+  Each line has the form "key = value" or "key: value". Whitespace around
+  key and value is ignored. Empty lines and lines starting with '#' are ignored.
+  If the same key occurs multiple times, the last one wins.
+*/
+func ReadConfig(r io.Reader) (map[string]string, error) {
+  config := map[string]string{}
+  scanner := bufio.NewScanner(r)
+  for lineno := 1; scanner.Scan(); lineno++ {
+    line := strings.TrimSpace(scanner.Text())
+    if line == "" || line[0] == '#' { continue }
+    
+    sep := strings.IndexAny(line, "=:")
+    if sep <= 0 {
+      return nil, fmt.Errorf("Config line %v: Expected \"key = value\" but got \"%v\"", lineno, line)
+    }
+    config[strings.TrimSpace(line[:sep])] = strings.TrimSpace(line[sep+1:])
+  }
+  return config, scanner.Err()
+}
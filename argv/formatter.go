@@ -299,7 +299,8 @@ func (l *LinePartIterator) Subrow() int {
 
 /*
  Returns the width in screen columns of the part returned by Data().
- Takes multi-byte UTF-8 sequences and wide characters into account.
+ Takes multi-byte UTF-8 sequences, wide characters, zero-width characters and
+ grapheme clusters (e.g. letters with combining marks) into account.
 */
 func (l *LinePartIterator) ScreenLength() int {
   return l.screenlen
@@ -334,22 +335,16 @@ func (l *LinePartIterator) update_length() {
   help := l.usage[l.rowdesc].Help
   ptr := l.ptr
   for ptr < len(help) && help[ptr] != '\v' && help[ptr] != '\t' && help[ptr] != '\n' {
-    l.length++
-    l.screenlen++
-    startbyte := help[ptr]
-    ptr++
-    if (startbyte > 0xC1) { // everything <= 0xC1 (yes, even 0xC1 itself) is not a valid UTF-8 start byte
-      ch := uint(clear_utf8_len(startbyte)) // mask out length bits, we don't verify their correctness
-      for ptr < len(help) && help[ptr] ^ 0x80 <= 0x3F { // while next byte is continuation byte
-        ch = (ch << 6) ^ uint(help[ptr] ^ 0x80) // add continuation to char code
-        ptr++
-        l.length++
-      }
-      // ch is the decoded unicode code point
-      if (ch >= 0x1100 && isWideChar(ch)) { // the test for 0x1100 is here to avoid the function call in the Latin case
-        l.screenlen++
-      }
+    if esc := escape_len(help, ptr); esc > 0 { // escape sequences take up no screen space
+      ptr += esc
+      l.length += esc
+      continue
     }
+    // '\v', '\t' and '\n' are control characters, so a grapheme cluster never extends past them
+    end, width := next_cluster(help, ptr)
+    l.length += end - ptr
+    l.screenlen += width
+    ptr = end
   }
 }
 
@@ -365,10 +360,92 @@ func (w *stringwriter) WriteString(s string) (n int, err error) {
   return len(s), nil
 }
 
-func formatUsage(usage Usage) string {
-  write := &stringwriter{}
+/*
+ Settings for formatting help texts as described in Usage.String().
+ A Formatter is an alternative to the package variables Columns,
+ LastColumnMinPercent and LastColumnOwnLineMaxPercent, which are shared
+ by all goroutines. Create a Formatter with NewFormatter() or by
+ filling in all fields yourself.
+*/
+type Formatter struct {
+  /*
+    Number of screen columns. If <= 0, the environment variable COLUMNS is
+    used. If COLUMNS is empty or cannot be parsed as an integer, 80 is used.
+    NewFormatter() sets this to the width of the terminal.
+  */
+  Columns int
+  
+  // Like the package variable LastColumnMinPercent. NewFormatter() sets this to 50.
+  LastColumnMinPercent int
   
-  width := Columns
+  // Like the package variable LastColumnOwnLineMaxPercent. NewFormatter() sets this to 75.
+  LastColumnOwnLineMaxPercent int
+  
+  // Used for highlighting. May be nil.
+  Style *Style
+}
+
+/*
+ Returns a new Formatter for output to terminal (typically os.Stdout).
+ Columns is set to the width of the terminal as reported by the operating
+ system (see TerminalWidth()). If terminal is not a terminal (or nil),
+ the environment variable COLUMNS is used and if that is not available 80.
+ Style is set to HelpStyle unless highlighting is disabled (see HelpStyle).
+*/
+func NewFormatter(terminal *os.File) *Formatter {
+  return &Formatter{Columns:line_width(0, terminal), LastColumnMinPercent:50, LastColumnOwnLineMaxPercent:75,
+                    Style:style_for(terminal)}
+}
+
+/*
+ Returns the Formatter used by Usage.String(), based on the package variables
+ Columns, LastColumnMinPercent, LastColumnOwnLineMaxPercent and HelpStyle.
+*/
+func defaultFormatter() *Formatter {
+  return &Formatter{Columns:line_width(Columns, os.Stdout), LastColumnMinPercent:LastColumnMinPercent,
+                    LastColumnOwnLineMaxPercent:LastColumnOwnLineMaxPercent, Style:style_for(os.Stdout)}
+}
+
+/*
+ Returns the Help texts of usage, formatted as described in Usage.String().
+ Format() does not access any package variables, so it is safe to call
+ concurrently with other goroutines that use different Formatters or
+ modify the package variables.
+*/
+func (f *Formatter) Format(usage Usage) string {
+  write := &stringwriter{}
+  formatTables(write, usage.display(), f.layout(8)) // 8 columns are enough for everyone
+  return strings.Join(*write, "")
+}
+
+/*
+ Returns t formatted as described for Table. If t.Width > 0 it
+ overrides f.Columns. f.Style is not used.
+*/
+func (f *Formatter) FormatTable(t *Table) string {
+  write := &stringwriter{}
+  formatTables(write, t.usage(), t.layout(f))
+  return strings.Join(*write, "")
+}
+
+// Returns the parameters for formatTables() corresponding to f.
+func (f *Formatter) layout(maxcolumns int) tablelayout {
+  return tablelayout{width:line_width(f.Columns, nil), style:f.Style, maxcolumns:maxcolumns,
+                     last_column_min_percent:f.LastColumnMinPercent,
+                     last_column_own_line_max_percent:f.LastColumnOwnLineMaxPercent}
+}
+
+/*
+ Returns the number of screen columns to use for formatting if
+ columns is the requested number. If columns <= 0, the width of terminal
+ (if it is non-nil and a terminal), the environment variable COLUMNS or 80
+ is used (in that order).
+*/
+func line_width(columns int, terminal *os.File) int {
+  width := columns
+  if width <= 0 && terminal != nil {
+    width = TerminalWidth(terminal)
+  }
   if width <= 0 {
     var err error
     width, err = strconv.Atoi(os.Getenv("COLUMNS"))
@@ -382,9 +459,45 @@ func formatUsage(usage Usage) string {
   if (width > 10000) { // protect against overflow in the following computation
     width = 10000
   }
+  
+  return width
+}
+
+/*
+ Returns columns[i] or the zero ColumnFormat if columns has no element i.
+*/
+func column_format(columns []ColumnFormat, i int) ColumnFormat {
+  if i < len(columns) { return columns[i] }
+  return ColumnFormat{}
+}
+
+// Parameters for formatTables().
+type tablelayout struct {
+  // number of screen columns available
+  width int
+  // used for highlighting (may be nil)
+  style *Style
+  // alignment and width limits of the individual columns (may be nil)
+  columns []ColumnFormat
+  // columns beyond maxcolumns-1 are dropped
+  maxcolumns int
+  // written between columns (in addition to the spaces contained in the cells)
+  separator string
+  // see LastColumnMinPercent and LastColumnOwnLineMaxPercent
+  last_column_min_percent int
+  last_column_own_line_max_percent int
+}
 
-  last_column_min_width := ((width * LastColumnMinPercent) + 50) / 100
-  last_column_own_line_max_width := ((width * LastColumnOwnLineMaxPercent) + 50) / 100
+/*
+ Writes the Help texts of usage to write, formatted as described in
+ Usage.String() according to layout.
+*/
+func formatTables(write io.Writer, usage Usage, layout tablelayout) {
+  width, style, columns, maxcolumns := layout.width, layout.style, layout.columns, layout.maxcolumns
+  gap := screen_width(layout.separator)
+  
+  last_column_min_width := ((width * layout.last_column_min_percent) + 50) / 100
+  last_column_own_line_max_width := ((width * layout.last_column_own_line_max_percent) + 50) / 100
   if (last_column_own_line_max_width == 0) {
     last_column_own_line_max_width = 1
   }
@@ -395,8 +508,7 @@ func formatUsage(usage Usage) string {
 
     /***************** Determine column widths *******************************/
 
-    const maxcolumns = 8; // 8 columns are enough for everyone
-    var col_width [maxcolumns]int
+    col_width := make([]int, maxcolumns)
     var lastcolumn int
     var leftwidth int
     overlong_column_threshold := 10000
@@ -436,27 +548,41 @@ func formatUsage(usage Usage) string {
        * except for a few overlong fragments.
        * */
 
+      for i := 0; i <= lastcolumn; i++ {
+        if max := column_format(columns, i).MaxWidth; max > 0 && col_width[i] > max {
+          col_width[i] = max
+        }
+      }
+
       leftwidth = 0;
       overlong_column_threshold = 0;
       for i := 0; i < lastcolumn; i++ {
-        leftwidth += col_width[i];
         upmax(&overlong_column_threshold, col_width[i]);
+        upmax(&col_width[i], column_format(columns, i).MinWidth)
+        leftwidth += col_width[i] + gap;
       }
 
-      if (leftwidth <= width) { break }
+      // If the minimum widths alone exceed the screen width, there's nothing we can do.
+      if (leftwidth <= width || overlong_column_threshold == 0) { break }
     }
 
     /**************** Determine tab stops and last column handling **********************/
 
-    var tabstop [maxcolumns]int
+    tabstop := make([]int, maxcolumns)
     tabstop[0] = 0;
     for i := 1; i < maxcolumns; i++ {
-      tabstop[i] = tabstop[i - 1] + col_width[i - 1];
+      tabstop[i] = tabstop[i - 1] + col_width[i - 1] + gap;
+    }
+
+    lastformat := column_format(columns, lastcolumn)
+    min_width := last_column_min_width
+    if lastformat.MinWidth > 0 {
+      min_width = lastformat.MinWidth
     }
 
     rightwidth := width - tabstop[lastcolumn]
     print_last_column_on_own_line := false
-    if rightwidth < last_column_min_width &&  // if we don't have the minimum requested width for the last column
+    if rightwidth < min_width &&              // if we don't have the minimum requested width for the last column
        ( col_width[lastcolumn] == 0 ||        // and all last columns are > overlong_column_threshold
        rightwidth < col_width[lastcolumn]) {  // or there is at least one last column that requires more than the space available
       print_last_column_on_own_line = true;
@@ -475,7 +601,17 @@ func formatUsage(usage Usage) string {
       print_last_column_on_own_line = false
     }
 
-    lastColumnLineWrapper := NewColumnWrapper(width - rightwidth, width)
+    wrapwidth := rightwidth
+    if lastformat.MaxWidth > 0 && lastformat.MaxWidth < wrapwidth {
+      wrapwidth = lastformat.MaxWidth
+    }
+    lastColumnLineWrapper := NewColumnWrapper(width - rightwidth, width - rightwidth + wrapwidth)
+    lastColumnLineWrapper.align = lastformat.Align
+    lastColumnLineWrapper.alignwidth = col_width[lastcolumn]
+    upmax(&lastColumnLineWrapper.alignwidth, lastformat.MinWidth)
+    if lastColumnLineWrapper.alignwidth > wrapwidth {
+      lastColumnLineWrapper.alignwidth = wrapwidth
+    }
     interjectionLineWrapper := NewColumnWrapper(0, width)
 
     part.RestartTable()
@@ -500,8 +636,20 @@ func formatUsage(usage Usage) string {
 
         if ((part.Column() < lastcolumn) && (part.Column() > 0 || part.Subrow() > 0 || part.PartTerminator() == '\t' || 
             part.PartTerminator() == '\v')) {
-          io.WriteString(write, part.Data())
+          if align := column_format(columns, part.Column()).Align; align != ALIGN_LEFT {
+            pad := col_width[part.Column()] - part.ScreenLength()
+            if align == ALIGN_CENTER { pad /= 2 }
+            if pad > 0 { indent(write, &x, x + pad) }
+          }
+          io.WriteString(write, style.options(part.Data()))
           x += part.ScreenLength()
+          if gap > 0 {
+            if x < tabstop[part.Column()] + col_width[part.Column()] {
+              indent(write, &x, tabstop[part.Column()] + col_width[part.Column()])
+            }
+            io.WriteString(write, layout.separator)
+            x += gap
+          }
           
         } else { // either part.Column() == lastcolumn or we are in the special case of
                  // an interjection that doesn't contain \v or \t
@@ -510,14 +658,16 @@ func formatUsage(usage Usage) string {
           // each line, because some rows may have fewer columns.
 
           var lineWrapper *ColumnWrapper
+          data := part.Data()
           if (part.Column() == 0) {
             lineWrapper = interjectionLineWrapper
+            data = style.header(data)
           } else {
             lineWrapper = lastColumnLineWrapper
           }
 
           if (!print_last_column_on_own_line || part.Column() != lastcolumn) {
-            lineWrapper.Process(write, part.Data())
+            lineWrapper.Process(write, data)
           }
         }
       } // for
@@ -539,8 +689,6 @@ func formatUsage(usage Usage) string {
       interjectionLineWrapper.Flush(write);
     }
   }  
-  
-  return strings.Join(*write, "")
 }
 
 /*
@@ -586,6 +734,22 @@ type ColumnWrapper struct {
   
   // The width of the column to wrap.
   width int
+  
+  // ALIGN_LEFT, ALIGN_RIGHT or ALIGN_CENTER. Lines are aligned within alignwidth columns.
+  align int
+  alignwidth int
+}
+
+/*
+ Appends a line to the buffer, preceded by the spaces required for alignment.
+*/
+func (w *ColumnWrapper) push(line string) {
+  if w.align != ALIGN_LEFT {
+    pad := w.alignwidth - screen_width(strings.TrimRight(line, " "))
+    if w.align == ALIGN_CENTER { pad /= 2 }
+    if pad > 0 { line = strings.Repeat(" ", pad) + line }
+  }
+  w.buf = append(w.buf, line)
 }
 
 /*
@@ -645,53 +809,53 @@ func (w *ColumnWrapper) Flush(write io.Writer) {
 func (w *ColumnWrapper) Process(write io.Writer, data string) {
   for data != "" {
     if len(data) <= w.width { // quick test that works because utf8width <= len (all wide chars have at least 2 bytes)
-      w.buf = append(w.buf, data)
+      w.push(data)
       data = ""
     } else { // if (len(data) > width)  it's possible (but not guaranteed) that utf8width > width
       utf8width := 0
       maxi := 0
       for maxi < len(data) && utf8width < w.width {
-        charbytes := 1
-        startbyte := data[maxi]
-        if startbyte > 0xC1 { // everything <= 0xC1 (yes, even 0xC1 itself) is not a valid UTF-8 start byte
-          ch := uint(clear_utf8_len(startbyte)) 
-          for maxi+charbytes < len(data) && data[maxi+charbytes] ^ 0x80 <= 0x3F { // while next byte is continuation byte
-            ch = (ch << 6) ^ uint(data[maxi + charbytes] ^ 0x80) // add continuation to char code
-            charbytes++
-          }
-          // ch is the decoded unicode code point
-          if (ch >= 0x1100 && isWideChar(ch)) { // the test for 0x1100 is here to avoid the function call in the Latin case
-            if (utf8width + 2 > w.width) {
-              break
-            }
-            utf8width++
-          }
+        if esc := escape_len(data, maxi); esc > 0 { // escape sequences take up no screen space
+          maxi += esc
+          continue
+        }
+        end, width := next_cluster(data, maxi)
+        if utf8width + width > w.width {
+          break
         }
-        utf8width++
-        maxi += charbytes
+        utf8width += width
+        maxi = end
+      }
+      
+      // Escape sequences directly following the last character that fits (e.g. the
+      // sequence that ends highlighting) belong on the same line.
+      for esc := escape_len(data, maxi); esc > 0; esc = escape_len(data, maxi) {
+        maxi += esc
       }
 
-      // data[maxi-1] is the last byte of the UTF-8 sequence of the last character that fits
-      // onto the 1st line. If maxi == len, all characters fit on the line.
+      // data[maxi-1] is the last byte of the last grapheme cluster that fits
+      // onto the 1st line. If maxi == len, all clusters fit on the line.
 
       if maxi == len(data) {
-        w.buf = append(w.buf, data)
+        w.push(data)
         data = ""
-      } else { // if (maxi < len)  at least 1 character (data[maxi] that is) doesn't fit on the line
+      } else { // if (maxi < len)  at least 1 cluster (starting at data[maxi] that is) doesn't fit on the line
         
-        // try to find a ' ' as split point
+        // try to find a ' ' as split point (but not a ' ' that carries combining marks)
         i := maxi
         for i >= 0 {
-          if data[i] == ' ' { break }
+          if data[i] == ' ' {
+            if end, _ := next_cluster(data, i); end == i+1 { break }
+          }
           i--
         }
 
         if i >= 0 { // if we found a ' ' as split point
-          w.buf = append(w.buf, data[:i])
+          w.push(data[:i])
           data = data[i+1:] // i+1 because we discard the ' '
         } else // did not find a space to split at => split before data[maxi]
-        { // data[maxi] is always the beginning of a character, never a continuation byte
-          w.buf = append(w.buf, data[:maxi])
+        { // data[maxi] is always the beginning of a grapheme cluster, never inside of one
+          w.push(data[:maxi])
           data = data[maxi:] // NOT maxi+1 ! We don't discard a character here.
         }
       }
@@ -705,29 +869,11 @@ func (w *ColumnWrapper) Process(write io.Writer, data string) {
 // has x coordinate x1 and whose last character has x coordinate x2-1.
 func NewColumnWrapper(x1, x2 int) *ColumnWrapper {
   width := x2 - x1
-  // because of wide characters we need at least width 2 or the code breaks
+  // because of wide grapheme clusters we need at least width 2 or the code breaks
   if width < 2 { width = 2 }
   return &ColumnWrapper{x:x1, width:width}
 }
 
-// Returns b with leading 1 bits (which determine the length of a
-// UTF-8 byte sequence) cleared.
-func clear_utf8_len(b byte) byte {
-  b2 := b ^ 128
-  if b2 > b { return b }
-  b = b2 ^ 64
-  if b > b2 { return b2 }
-  b2 = b ^ 32
-  if b2 > b { return b }
-  b = b2 ^ 16
-  if b > b2 { return b2 }
-  b2 = b ^ 8
-  if b2 > b { return b }
-  b = b2 ^ 4
-  if b > b2 { return b2 }
-  return b
-}
-
 /*
  Sets *i1 = max(i1, i2)
 */
@@ -737,33 +883,6 @@ func upmax(i1 *int, i2 int) {
   }
 }
 
-/*
- Returns true if ch is the unicode code point of a wide character.
-
- The following character ranges are treated as wide
-
- 1100..115F
- 2329..232A  (just 2 characters!)
- 2E80..A4C6  except for 303F
- A960..A97C
- AC00..D7FB
- F900..FAFF
- FE10..FE6B
- FF01..FF60
- FFE0..FFE6
- 1B000......
-*/
-func isWideChar(ch uint) bool {
-  if (ch == 0x303F) {
-    return false;
-  }
-
-  return ((0x1100 <= ch && ch <= 0x115F) || (0x2329 <= ch && ch <= 0x232A) || (0x2E80 <= ch && ch <= 0xA4C6) ||
-          (0xA960 <= ch && ch <= 0xA97C) || (0xAC00 <= ch && ch <= 0xD7FB) || (0xF900 <= ch && ch <= 0xFAFF) ||
-          (0xFE10 <= ch && ch <= 0xFE6B) || (0xFF01 <= ch && ch <= 0xFF60) || (0xFFE0 <= ch && ch <= 0xFFE6) ||
-          (0x1B000 <= ch));
-}
-
 /*
  Moves the "cursor" to column want_x assuming it is currently at column x
  and sets x=want_x .
@@ -0,0 +1,201 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named graphemes.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+
+package argv
+
+import (
+         "sort"
+         "unicode/utf8"
+       )
+
+// An inclusive range of code points.
+type runerange struct {
+  first, last rune
+}
+
+// An inclusive range of code points that share a Grapheme_Cluster_Break property.
+type propertyrange struct {
+  first, last rune
+  property int
+}
+
+// Grapheme_Cluster_Break property values (see grapheme_table).
+const (
+  gb_other = iota
+  gb_cr
+  gb_lf
+  gb_control
+  gb_extend
+  gb_zwj
+  gb_regional
+  gb_prepend
+  gb_spacingmark
+  gb_l
+  gb_v
+  gb_t
+  gb_lv
+  gb_lvt
+  gb_pictographic
+)
+
+// Returns the Grapheme_Cluster_Break property of r (or gb_pictographic if
+// r is Extended_Pictographic).
+func grapheme_property(r rune) int {
+  if r < 0x7F {
+    if r >= 0x20 { return gb_other }
+    if r == '\r' { return gb_cr }
+    if r == '\n' { return gb_lf }
+    return gb_control
+  }
+  
+  if r >= 0xAC00 && r <= 0xD7A3 { // Hangul syllables
+    if (r - 0xAC00) % 28 == 0 { return gb_lv }
+    return gb_lvt
+  }
+  
+  i := sort.Search(len(grapheme_table), func(i int) bool { return grapheme_table[i].last >= r })
+  if i < len(grapheme_table) && grapheme_table[i].first <= r {
+    return grapheme_table[i].property
+  }
+  return gb_other
+}
+
+// Returns true if r is a wide or fullwidth character according to
+// the East_Asian_Width property.
+func is_wide(r rune) bool {
+  if r < 0x1100 { return false } // fast path for Latin etc.
+  i := sort.Search(len(wide_table), func(i int) bool { return wide_table[i].last >= r })
+  return i < len(wide_table) && wide_table[i].first <= r
+}
+
+/*
+ Returns the number of screen columns taken up by r if it is
+ displayed on its own:
+ 
+   0 for control characters, zero-width characters (e.g. U+200B),
+     combining marks and the conjoining Hangul vowels and final consonants.
+   2 for wide and fullwidth characters.
+   1 for everything else (including characters of ambiguous width).
+*/
+func rune_width(r rune) int {
+  switch grapheme_property(r) {
+    case gb_control, gb_cr, gb_lf, gb_extend, gb_zwj, gb_v, gb_t: return 0
+  }
+  if is_wide(r) { return 2 }
+  return 1
+}
+
+/*
+ Finds the extended grapheme cluster (as defined by Unicode Standard Annex #29)
+ that starts at byte index i of s. Returns the index of the 1st byte after the
+ cluster and the number of screen columns the cluster takes up.
+ A grapheme cluster is what the user perceives as a single character,
+ e.g. a letter followed by combining accents, a Hangul syllable made up of
+ conjoining jamo or an emoji sequence joined with ZERO WIDTH JOINER (U+200D).
+ 
+ The width of a cluster is the greatest rune_width() of its code points,
+ except for emoji followed by VARIATION SELECTOR-16 (U+FE0F) and pairs of
+ regional indicators (i.e. flags), which are 2 columns wide.
+ 
+ Invalid UTF-8 bytes are treated as individual clusters of width 1.
+*/
+func next_cluster(s string, i int) (end int, width int) {
+  r, n := utf8.DecodeRuneInString(s[i:])
+  end = i + n
+  if r == utf8.RuneError && n <= 1 { return end, 1 }
+  
+  prop := grapheme_property(r)
+  first := prop
+  width = rune_width(r)
+  emoji := prop == gb_pictographic // true if the cluster so far ends in Extended_Pictographic Extend*
+  emoji_zwj := false // true if the cluster so far ends in Extended_Pictographic Extend* ZWJ
+  regional := 0 // number of regional indicators at the end of the cluster so far
+  if prop == gb_regional { regional = 1 }
+  
+  for end < len(s) {
+    r, n = utf8.DecodeRuneInString(s[end:])
+    if r == utf8.RuneError && n <= 1 { break }
+    next := grapheme_property(r)
+    
+    join := false
+    switch {
+      case prop == gb_cr && next == gb_lf:                                     // GB3
+        join = true
+      case prop == gb_cr || prop == gb_lf || prop == gb_control ||            // GB4
+           next == gb_cr || next == gb_lf || next == gb_control:               // GB5
+        join = false
+      case prop == gb_l && (next == gb_l || next == gb_v || next == gb_lv || next == gb_lvt): // GB6
+        join = true
+      case (prop == gb_lv || prop == gb_v) && (next == gb_v || next == gb_t): // GB7
+        join = true
+      case (prop == gb_lvt || prop == gb_t) && next == gb_t:                  // GB8
+        join = true
+      case next == gb_extend || next == gb_zwj || next == gb_spacingmark:     // GB9, GB9a
+        join = true
+      case prop == gb_prepend:                                                // GB9b
+        join = true
+      case emoji_zwj && next == gb_pictographic:                              // GB11
+        join = true
+      case regional % 2 == 1 && next == gb_regional:                          // GB12, GB13
+        join = true
+    }
+    if !join { break }
+    
+    end += n
+    upmax(&width, rune_width(r))
+    if r == 0xFE0F && first == gb_pictographic { width = 2 }
+    
+    switch next {
+      case gb_pictographic: emoji, emoji_zwj = true, false
+      case gb_extend: emoji_zwj = false
+      case gb_zwj: emoji, emoji_zwj = false, emoji
+      default: emoji, emoji_zwj = false, false
+    }
+    
+    if next == gb_regional {
+      regional++
+      width = 2
+    } else {
+      regional = 0
+    }
+    
+    prop = next
+  }
+  
+  return end, width
+}
+
+// Returns the number of screen columns taken up by s. ANSI escape sequences
+// (see Style) take up no space.
+func screen_width(s string) int {
+  width := 0
+  for i := 0; i < len(s); {
+    if esc := escape_len(s, i); esc > 0 {
+      i += esc
+      continue
+    }
+    var w int
+    i, w = next_cluster(s, i)
+    width += w
+  }
+  return width
+}
@@ -0,0 +1,185 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named meta.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+
+package argv
+
+import (
+         "sync"
+         "unsafe"
+       )
+
+/*
+  Additional information about an option that has no place among the
+  OptionInfo fields. It is attached to the OptionInfo's ArgChecker with
+  WithMeta(), so Usage literals keep their usual form, e.g.
+  
+    { OLDOUT, 0, "O", "", argv.WithMeta(argv.ArgRequired, argv.OptionMeta{Group:"Output", 
+                          Deprecated:"-O is deprecated, use --output instead"}),
+      "  -O <file>  \tSame as --output." },
+  
+  Parse(), ParseCommands(), the help output (Usage.String(), Formatter, Troff(),
+  Markdown()) and CompletionScript() all take it into account.
+*/
+type OptionMeta struct {
+  /*
+    Name of the help section the option is shown in (see Usage.String()).
+    "" means the option stays in the main section.
+  */
+  Group string
+  
+  /*
+    If true, the option is parsed normally but it is left out of the help
+    output and completion scripts.
+  */
+  Hidden bool
+  
+  /*
+    If not "", the option is deprecated and Parse() passes this message to
+    Warn whenever the option is used.
+  */
+  Deprecated string
+  
+  /*
+    Name of an environment variable (e.g. "FOO_PORT") that provides the
+    option's argument if the option is missing from the argument vector.
+    Parse() then synthesizes an Option with the OptionInfo's long name (or
+    short name if there is no long name), the variable's value as attached
+    argument and Source "$"+Env. The ArgChecker is called as usual. For options
+    that take no argument (i.e. the ArgChecker returns ARG_NONE), the value is
+    interpreted as a boolean (see strconv.ParseBool()) and the option is
+    synthesized only if the value is true.
+    This gives the usual priority order argument vector > environment > Config.
+  */
+  Env string
+  
+  /*
+    Key in Config that provides the option's argument if the option is
+    missing from the argument vector and the environment. The Option is
+    synthesized as described for Env, with Source "config key "+Key.
+  */
+  Key string
+}
+
+/*
+  Called by Parse() and ParseCommands() after successful parsing for every
+  Option in alloptions whose OptionMeta has a Deprecated message, in the order
+  of alloptions. Deprecated options are otherwise treated like any other option,
+  i.e. they don't cause an error. If Warn is nil (the default), deprecation
+  messages are ignored.
+*/
+var Warn func(option *Option, message string)
+
+/*
+  Returns an ArgChecker that works exactly like check and in addition carries
+  meta for the OptionInfo that uses it (see OptionMeta and OptionInfo.Meta()).
+  Every call returns a new ArgChecker. The meta is remembered for the lifetime
+  of the program, so WithMeta() is meant for Usages that are created once,
+  not for every Parse().
+*/
+func WithMeta(check ArgChecker, meta OptionMeta) ArgChecker {
+  var wrapper ArgChecker = func(option *Option) error {
+    return check(option)
+  }
+  meta_mutex.Lock()
+  defer meta_mutex.Unlock()
+  metas[checkerIdentity(wrapper)] = &meta
+  return wrapper
+}
+
+/*
+  Returns the OptionMeta that info.CheckArg carries (see WithMeta()) or the zero
+  OptionMeta if it carries none. info.CheckArg is not called.
+*/
+func (info *OptionInfo) Meta() OptionMeta {
+  if info.CheckArg == nil { return OptionMeta{} }
+  meta_mutex.Lock()
+  defer meta_mutex.Unlock()
+  if meta := metas[checkerIdentity(info.CheckArg)]; meta != nil { return *meta }
+  return OptionMeta{}
+}
+
+// The OptionMetas of all ArgCheckers returned by WithMeta(), keyed by checkerIdentity().
+var metas = map[unsafe.Pointer]*OptionMeta{}
+var meta_mutex sync.Mutex
+
+/*
+  Returns a pointer that identifies check. Go does not allow comparing func values,
+  but a func value is a pointer to the function's closure, which is allocated anew
+  for every closure created by WithMeta(), so the pointer is unique for each of them.
+*/
+func checkerIdentity(check ArgChecker) unsafe.Pointer {
+  return *(*unsafe.Pointer)(unsafe.Pointer(&check))
+}
+
+/*
+  Returns usage as it is shown in the help output:
+  
+   * OptionInfos whose OptionMeta is Hidden are left out.
+   
+   * OptionInfos without a Group remain in their original order at the start
+     (including section headers etc.).
+   
+   * For each group (in the order of their first occurrence in usage), a table
+     break ("\f") and a header row with the group name followed by ":" is
+     appended, followed by the OptionInfos of that group in their original order.
+     Because of the table break, each group's columns are aligned independently.
+*/
+func (usage Usage) display() Usage {
+  shown := Usage{}
+  groups := []string{}
+  members := map[string]Usage{}
+  for i := range usage {
+    meta := usage[i].Meta()
+    if meta.Hidden { continue }
+    if meta.Group == "" {
+      shown = append(shown, usage[i])
+      continue
+    }
+    if _, seen := members[meta.Group]; !seen {
+      groups = append(groups, meta.Group)
+    }
+    members[meta.Group] = append(members[meta.Group], usage[i])
+  }
+  
+  for _, group := range groups {
+    shown = append(shown, OptionInfo{-1, 0, "", "", ArgUnknown, "\f"})
+    header := group + ":"
+    if len(shown) > 1 { header = "\n" + header }
+    shown = append(shown, OptionInfo{-1, 0, "", "", ArgUnknown, header})
+    shown = append(shown, members[group]...)
+  }
+  
+  return shown
+}
+
+/*
+  Calls Warn for every option in alloptions that is deprecated.
+*/
+func warnDeprecated(alloptions []*Option) {
+  if Warn == nil { return }
+  for _, option := range alloptions {
+    if option.Info == nil { continue }
+    if meta := option.Info.Meta(); meta.Deprecated != "" {
+      Warn(option, meta.Deprecated)
+    }
+  }
+}
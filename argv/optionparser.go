@@ -30,10 +30,16 @@ import (
 
 /*
   Number of screen columns for formatting usage in function Usage.String().
-  If Columns I<= 0, then the environment variable COLUMNS is used. If COLUMNS
+  If Columns I<= 0, then the width of the terminal os.Stdout is used (see TerminalWidth()).
+  If os.Stdout is not a terminal, the environment variable COLUMNS is used. If COLUMNS
   is empty or cannot be parsed as an integer, 80 is used.
+  
+  NOTE: Columns, LastColumnMinPercent and LastColumnOwnLineMaxPercent are shared by
+  all goroutines. If you need different settings in different goroutines, use a Formatter.
   NOTE: Asian wide characters are supported by Usage.String() and count as
-  2 screen columns.
+  2 screen columns. Combining marks and zero-width characters count as 0 and
+  Usage.String() never breaks a line inside of a grapheme cluster (e.g. between
+  a letter and its combining accent or within an emoji sequence).
 */
 var Columns = 0
 
@@ -209,6 +215,14 @@ type Option struct {
   */
   Value interface{}
   
+  /*
+    Where the option came from if it was not taken from the argument vector.
+    "" for options from the argument vector, "$NAME" for options from the
+    environment variable NAME and "config key KEY" for options taken from
+    Config (see OptionMeta.Env and OptionMeta.Key).
+  */
+  Source string
+  
   /*
     Points to the next Option with the same Info.Id. If isNotLast==false, this
     points to the FIRST Option with the same Info.Id in the argument vector.
@@ -218,7 +232,13 @@ type Option struct {
 }
 
 // Returns the name of the option, so that Option objects can be used with *printf().
-func (o *Option) String() string { return o.Name }
+// If the option did not come from the argument vector, its Source is appended,
+// e.g. "--port (from $FOO_PORT)", so that error messages from ArgCheckers
+// point the user to the right place.
+func (o *Option) String() string { 
+  if o.Source != "" { return o.Name + " (from " + o.Source + ")" }
+  return o.Name 
+}
 
 /*
   Returns the last option in the argument vector with the same Info.Id as o.
@@ -318,7 +338,11 @@ func (o *Option) Id() int {
 */
 func ArgUnknown(option *Option) error {
   if ambiguous, ok := option.Value.([]string); ok && len(ambiguous) == 2 {
-    return fmt.Errorf("Ambiguous abbreviation '%v'. Candiates: --%v, --%v", option, ambiguous[0], ambiguous[1])
+    prefix := "--"
+    if strings.HasPrefix(option.Name, "/") {
+      prefix = "/"
+    }
+    return fmt.Errorf("Ambiguous abbreviation '%v'. Candiates: %v%v, %v%v", option, prefix, ambiguous[0], prefix, ambiguous[1])
   }
   return fmt.Errorf("Unknown option '%v'", option)
 }
@@ -372,7 +396,7 @@ func ArgInt(option *Option) error {
   ArgChecker for options that do not have an argument.
 */
 func ArgNone(option *Option) error {
-  return ARG_NONE_Err(option.Name)
+  return ARG_NONE_Err(option.String())
 }
 
 /*
@@ -386,7 +410,7 @@ func ArgOptional(option *Option) error {
     if option.ArgAttached {
       return ARG_OK
     } else {
-      return ARG_NONE{fmt.Errorf("Option %v only accepts attached arguments", option.Name)}
+      return ARG_NONE{fmt.Errorf("Option %v only accepts attached arguments", option)}
     }
   }
   return ARG_NONE{fmt.Errorf("No argument given (but that's ok, it's optional)")}
@@ -420,6 +444,43 @@ func ArgOptional(option *Option) error {
            the full long option (e.g. "--foob=10" will be interpreted as if it was
            "--foobar=10" ), as long as the prefix has the required length AND IS UNAMBIGUOUS.
            
+    "/slash": This makes Parse() accept DOS/Windows-style options "/name" and
+              "/name:value" (or "/name=value") in addition to the usual syntax.
+              name may be a long option name, an abbreviation of one (if enabled
+              by the "--abb..." flag and subject to the same ambiguity rules)
+              or a single short option character. "/o:" passes an empty
+              argument to "-o" and "/v:1" is an error if "-v" takes no argument.
+              To avoid problems with absolute paths, words starting with "/"
+              that don't match any option are treated as non-options.
+              The Option.Name uses the "/" prefix (e.g. "/out").
+              
+    "+plus": This makes Parse() accept options with a "+" prefix that
+             negate the respective option. The word following the "+" is
+             interpreted like a word with a single "-" prefix with "-perl"
+             semantics (i.e. long option names take precedence over short option
+             groups). The resulting Option does not use the matched OptionInfo but
+             the first OptionInfo in the Usage with the same Id and a different State.
+             E.g. if "-f" is an abbreviation for "--enable-foo" (Id FOO,
+             State ENABLED) and "--disable-foo" has Id FOO and State DISABLED,
+             then "+f" is the same as "--disable-foo". If no such OptionInfo
+             exists, Parse() fails with an UNKNOWN_OPTION error.
+             Like with "/slash", words starting with "+" whose name matches neither
+             a long option nor (in its first character) a short option are
+             treated as non-options (e.g. "+5").
+             The Option.Name uses the "+" prefix (e.g. "+f").
+    
+    "@file": Every word "@path" in the argument vector is replaced by the words read
+             from the file path (a so-called response file). This is useful
+             for command lines that would exceed operating system limits.
+             The file is split into words like a POSIX shell would do (but
+             without expansions), i.e. single and double quotes and backslash
+             work as expected and '#' at the beginning of a word starts a comment.
+             Response files may contain "@path" words themselves. Relative paths
+             are relative to the current working directory.
+             A *ParseError for a word from a response file has Index set to the
+             index of the "@path" word in the original argument vector and
+             File and Line set to the location within the response file.
+                      
     Be careful if combining "-perl" and "--a" (i.e. abbreviations with only a 1 character
     prefix) because the ambiguity check does not consider short options and abbreviated
     single minus long options will take precedence over short options.
@@ -441,6 +502,8 @@ func ArgOptional(option *Option) error {
                 are treated as non-option arguments, even if they start with '-' .
     
     err: if non-nil something went wrong and the other return values have unspecified values.
+         Errors that concern a word of the argument vector are of type *ParseError.
+         They wrap the error returned by the ArgChecker.
     
     alloptions: contains all the parsed options from the argument vector in order, i.e.
                 alloptions[i] corresponds to the i-th option in the argument vector.
@@ -448,33 +511,48 @@ func ArgOptional(option *Option) error {
                 by the OptionInfo.Id values. The alloptions slice is used to iterate
                 over all options found in the argument vector. The options slice is used
                 to access specific options directly.
+                Options taken from the environment or Config (see OptionMeta.Env)
+                follow after all options from the argument vector.
                 NOTE: Each option from the argument vector produces only one Option
                 structure. The same Option structures are referenced in the
                 returned options and alloptions slices.
 */
 func Parse(args []string, usage Usage, flags string) (options []*Option, nonoptions []string, err error, alloptions []*Option) {
+  var fl parseflags
+  fl, err = parseFlags(flags)
+  if err != nil { return }
+  
+  var origins []wordorigin
+  if fl.response_files {
+    args, origins, err = expandResponseFiles(args)
+    if err != nil { return }
+  }
+  
+  options, nonoptions, err, alloptions, _ = parse(args, usage, flags, nil)
+  err = relocateError(err, origins)
+  if err == nil { warnDeprecated(alloptions) }
+  return
+}
+
+/*
+  Like Parse() but if is_command != nil it is called for the first non-option
+  word in the argument vector. If it returns true, parsing stops at that word
+  and the remainder of the argument vector starting with that word is
+  returned as rest. Otherwise rest is nil.
+*/
+func parse(args []string, usage Usage, flags string, is_command func(string) bool) (options []*Option, nonoptions []string, err error, alloptions []*Option, rest []string) {
   maxindex := 0
   for _, info := range usage {
     if info.Id > maxindex { maxindex = info.Id }
   }
   options = make([]*Option, maxindex+1)
   
-  gnu := false
-  single_minus_longopt := false
-  min_abbr_len := 0
-  
-  for _, flg := range strings.Fields(flags) {
-    if flg == "gnu" {
-      gnu = true
-    } else if flg == "-perl" {
-      single_minus_longopt = true
-    } else if strings.HasPrefix(flg, "--abb") {
-      min_abbr_len = len(flg) - 2 // -2 for the "--"
-    } else {
-      err = fmt.Errorf("Parse(): Unknown word in flags: %v", flg)
-      return
-    }
-  }
+  var fl parseflags
+  fl, err = parseFlags(flags)
+  if err != nil { return }
+  gnu := fl.gnu
+  single_minus_longopt := fl.single_minus_longopt
+  min_abbr_len := fl.min_abbr_len
   
   numargs := len(args)
 
@@ -483,9 +561,50 @@ func Parse(args []string, usage Usage, flags string) (options []*Option, nonopti
   for numargs > 0 {
     param := args[argidx] // param can be --long-option, -srto or non-option argument
 
+    // "/opt:value" and "+opt" are translated into the equivalent "--opt=value"
+    // and "--opt" (or "-o" for short options) and prefix remembers the actual prefix
+    // for use in the Option.Name. Words that don't match any option are left alone
+    // and therefore treated as non-options.
+    // explicit_arg is set for "/o:value" because the value belongs to the short
+    // option even if it is empty or the option takes no argument.
+    prefix := ""
+    explicit_arg := false
+    if fl.slash && len(param) > 1 && param[0] == '/' && param[1] != '/' {
+      name := param[1:]
+      if sep := strings.IndexAny(name, ":="); sep >= 0 {
+        name = name[:sep] + "=" + name[sep+1:]
+      }
+      if usage.hasLong(name, min_abbr_len) {
+        param = "--" + name
+        prefix = "/"
+      } else if (len(name) == 1 || name[1] == '=') && usage.hasShort(name[0]) {
+        param = "-" + name[0:1]
+        if len(name) > 1 {
+          param += name[2:]
+          explicit_arg = true
+        }
+        prefix = "/"
+      }
+    } else if fl.plus && len(param) > 1 && param[0] == '+' && param[1] != '+' && param[1] != '-' {
+      if usage.hasLong(param[1:], min_abbr_len) {
+        param = "--" + param[1:]
+        prefix = "+"
+      } else if usage.hasShort(param[1]) {
+        param = "-" + param[1:]
+        prefix = "+"
+      }
+    }
+
     // in POSIX mode the first non-option argument terminates the option list
     // a lone minus character is a non-option argument
     if param == "" || param == "-" || param[0] != '-' {
+      // the first non-option word may select a sub-command
+      if is_command != nil && len(nonoptions) == 0 && is_command(param) {
+        rest = args[argidx:]
+        numargs = 0
+        break
+      }
+      
       if gnu {
         nonoptions = append(nonoptions, param)
         argidx++
@@ -596,7 +715,7 @@ func Parse(args []string, usage Usage, flags string) (options []*Option, nonopti
           idx++
         }
 
-        if len(param) == 1 { // if the potential argument is separate
+        if len(param) == 1 && !explicit_arg { // if the potential argument is separate
           if have_more_args {
             optarg = args[argidx+1]
             separate_arg = true
@@ -612,7 +731,8 @@ func Parse(args []string, usage Usage, flags string) (options []*Option, nonopti
 
       var descriptor *OptionInfo
       
-      if idx >= len(usage) { /**************  unknown option ********************/
+      unknown := idx >= len(usage)
+      if unknown { /**************  unknown option ********************/
         // look for dummy entry (Short == "" and Long == "") to use as descriptor for unknown options
         idx = 0
         for idx < len(usage) && (usage[idx].Short != "" || usage[idx].Long != "") {
@@ -649,6 +769,21 @@ func Parse(args []string, usage Usage, flags string) (options []*Option, nonopti
           option.Name = bettername
         }
         
+        if prefix != "" {
+          option.Name = prefix + strings.TrimLeft(option.Name, "-")
+        }
+        
+        if prefix == "+" && !unknown {
+          negated := usage.counterpart(descriptor)
+          if negated == nil {
+            err = &ParseError{Kind: UNKNOWN_OPTION, Index: argidx, Word: args[argidx], Option: option, 
+                              Err: fmt.Errorf("Option '%v' cannot be negated", option)}
+            return
+          }
+          descriptor = negated
+          option.Info = negated
+        }
+        
         if len(ambiguous) == 2 {
           option.Value = ambiguous
         }
@@ -666,28 +801,34 @@ func Parse(args []string, usage Usage, flags string) (options []*Option, nonopti
           handle_short_options = false;
           
         } else if _, ok := err.(ARG_NONE); ok {
-          if attached_arg && !handle_short_options { // if the argument is attached to a long option, we can't just ignore it
-            return          // => pass error to caller
+          if attached_arg && (!handle_short_options || explicit_arg) { // if the argument is attached to a long option (or "/o:"), we can't just ignore it
+            // => pass error to caller
+            err = &ParseError{Kind: UNEXPECTED_ARGUMENT, Index: argidx, Word: args[argidx], Info: descriptor, Option: option, Err: err}
+            return
           }
           option.HasArg = false
           option.Arg = ""
         } else {
+          perr := &ParseError{Kind: INVALID_ARGUMENT, Index: argidx, Word: args[argidx], Info: descriptor, Option: option, Err: err}
+          if unknown {
+            perr.Info = nil
+            perr.Kind = UNKNOWN_OPTION
+            if len(ambiguous) == 2 {
+              perr.Kind = AMBIGUOUS_OPTION
+            } else if strings.HasPrefix(perr.Word, "--") || use_bettername {
+              perr.Suggestions = usage.similarLongNames(longopt_name)
+            }
+          } else if !have_optarg || (separate_arg && len(optarg) > 1 && optarg[0] == '-') {
+            // There was no word after the option or the word is another option
+            // that we don't consume as argument. Either way the argument is missing.
+            perr.Kind = MISSING_ARGUMENT
+          }
+          err = perr
           return
         }
         
         alloptions = append(alloptions, option)
-        id := option.Info.Id
-        if id >= 0 {
-          if options[id] == nil {
-            options[id] = option
-            option.next = option
-          } else {
-            last := options[id].Last()
-            last.isNotLast = true
-            option.next = last.next
-            last.next = option
-          }
-        }
+        link(options, option)
       }
 
       if !handle_short_options { break }
@@ -703,11 +844,103 @@ func Parse(args []string, usage Usage, flags string) (options []*Option, nonopti
     numargs--
   }
 
-  err = nil
+  alloptions, err = usage.fallbacks(options, alloptions)
   return
 }
 
 
+// The flags of Parse() in decoded form.
+type parseflags struct {
+  gnu bool                  // "gnu"
+  single_minus_longopt bool // "-perl"
+  min_abbr_len int          // "--a", "--ab", "--abb",...
+  response_files bool       // "@file"
+  slash bool                // "/slash"
+  plus bool                 // "+plus"
+}
+
+// Decodes the flags string passed to Parse().
+func parseFlags(flags string) (fl parseflags, err error) {
+  for _, flg := range strings.Fields(flags) {
+    if flg == "gnu" {
+      fl.gnu = true
+    } else if flg == "-perl" {
+      fl.single_minus_longopt = true
+    } else if strings.HasPrefix(flg, "--abb") {
+      fl.min_abbr_len = len(flg) - 2 // -2 for the "--"
+    } else if flg == "@file" {
+      fl.response_files = true
+    } else if flg == "/slash" {
+      fl.slash = true
+    } else if flg == "+plus" {
+      fl.plus = true
+    } else {
+      err = fmt.Errorf("Parse(): Unknown word in flags: %v", flg)
+      return
+    }
+  }
+  return
+}
+
+/*
+ Returns true iff name (which may include "=value") is a long option name from
+ usage or (if min_abbr_len > 0) an abbreviation of one (whether ambiguous or not).
+*/
+func (usage Usage) hasLong(name string, min_abbr_len int) bool {
+  for i := range usage {
+    if usage[i].Long != "" && (streq(usage[i].Long, name) || (min_abbr_len > 0 && streqabbr(usage[i].Long, name, min_abbr_len))) {
+      return true
+    }
+  }
+  return false
+}
+
+/*
+ Returns true iff c is one of the Short characters of an entry in usage.
+*/
+func (usage Usage) hasShort(c byte) bool {
+  for i := range usage {
+    if instr(c, usage[i].Short) {
+      return true
+    }
+  }
+  return false
+}
+
+/*
+ Returns the first OptionInfo in usage with the same Id as info but a different State.
+ Returns nil if there is none.
+*/
+func (usage Usage) counterpart(info *OptionInfo) *OptionInfo {
+  for i := range usage {
+    if usage[i].Id == info.Id && usage[i].State != info.State {
+      return &usage[i]
+    }
+  }
+  return nil
+}
+
+/*
+ Appends option to the Next() chain starting at options[option.Id()] or
+ makes it the start of a new chain if there is none.
+ Does nothing if option.Id() < 0.
+*/
+func link(options []*Option, option *Option) {
+  id := option.Info.Id
+  if id >= 0 {
+    if options[id] == nil {
+      options[id] = option
+      option.next = option
+    } else {
+      last := options[id].Last()
+      last.isNotLast = true
+      option.next = last.next
+      last.next = option
+    }
+  }
+}
+
+
 /*
  Converts the OptionInfo.Help texts from usage into a nicely formatted
  "manpage" with support for multi-column layout and line-wrapping.
@@ -826,14 +1059,45 @@ func Parse(args []string, usage Usage, flags string) (options []*Option, nonopti
    -q                              Quick.
 
 
+ GROUPS AND HIDDEN OPTIONS
+ 
+ Options whose OptionMeta (see WithMeta()) has a Group are not shown at their
+ position in usage. Instead, for each group (in the order of their first occurrence)
+ a new table is started with a header row that contains the group name followed
+ by ":" and the options of that group in their original order. Options whose
+ OptionMeta is Hidden are not shown at all. E.g.
+ 
+   var usage = argv.Usage{ 
+   {..., "-v, --verbose  \tMore output." },
+   {..., argv.WithMeta(argv.ArgRequired, argv.OptionMeta{Group:"Output"}), "-o <file>  \tOutput file." },
+   {..., argv.WithMeta(argv.ArgNone, argv.OptionMeta{Hidden:true}), "--debug  \tDebug mode." },
+   {..., "-q, --quiet  \tLess output." }, ...
+ 
+   results in
+ 
+   -v, --verbose  More output.
+   -q, --quiet    Less output.
+   
+   Output:
+   -o <file>  Output file.
+ 
+ 
  LINE-WRAPPING
 
  Lines will be wrapped according to the global parameters Columns,
  LastColumnMinPercent and LastColumnOwnLineMaxPercent. See their documentation
- for details.
+ for details. Use a Formatter if you need different settings per call.
+ 
+ 
+ HIGHLIGHTING
+ 
+ If HelpStyle is set (e.g. to ANSI), option names, argument placeholders and
+ section headers are highlighted with terminal escape sequences. This is
+ automatically disabled if os.Stdout is not a terminal or the environment
+ variable NO_COLOR is set. See Style for details.
 */
 func (usage Usage) String() string {
-  return formatUsage(usage)
+  return defaultFormatter().Format(usage)
 }
 
 /*
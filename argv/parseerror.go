@@ -0,0 +1,169 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named parseerror.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package argv
+
+import (
+         "fmt"
+         "strings"
+       )
+
+// Kinds of ParseError.
+const (
+  // A word starting with "-" does not match any option.
+  UNKNOWN_OPTION = iota
+  // An abbreviated long option matches more than one option.
+  AMBIGUOUS_OPTION
+  // An option that requires an argument has none (i.e. it is the last word
+  // or it is followed by another option).
+  MISSING_ARGUMENT
+  // An argument is attached to an option that does not take one (e.g. "--help=foo").
+  UNEXPECTED_ARGUMENT
+  // The ArgChecker rejected the option's argument.
+  INVALID_ARGUMENT
+  // A response file (see the "@file" flag of Parse()) could not be read or parsed.
+  BAD_RESPONSE_FILE
+)
+
+/*
+  The type of errors returned by Parse() (and related functions) for
+  problems with a word in the argument vector.
+*/
+type ParseError struct {
+  // One of UNKNOWN_OPTION, AMBIGUOUS_OPTION, MISSING_ARGUMENT,
+  // UNEXPECTED_ARGUMENT, INVALID_ARGUMENT and BAD_RESPONSE_FILE.
+  Kind int
+  
+  // The index of Word in the argument vector or -1 if the option does not
+  // come from the argument vector (see Option.Source). If Word comes
+  // from a response file, this is the index of the "@path" word.
+  Index int
+  
+  // If Word comes from a response file (see the "@file" flag of Parse()), 
+  // the path of that file and the line on which Word starts. Otherwise ""
+  // and 0.
+  File string
+  Line int
+  
+  // The word in the argument vector that contains the offending option
+  // (e.g. "-abc" if the problem is with option "-b").
+  Word string
+  
+  // The OptionInfo that matched the option. nil for UNKNOWN_OPTION and
+  // AMBIGUOUS_OPTION.
+  Info *OptionInfo
+  
+  // The option as passed to the ArgChecker.
+  Option *Option
+  
+  // The error returned by the ArgChecker.
+  Err error
+  
+  // For UNKNOWN_OPTION errors on long options this contains the names
+  // (including "--") of similar long options from the Usage, if any.
+  Suggestions []string
+}
+
+// Returns the message of the ArgChecker error, followed by suggestions (if any).
+// If the error is located in a response file, the message is prefixed with "File:Line: ".
+func (e *ParseError) Error() string {
+  msg := e.Err.Error()
+  if e.File != "" { msg = fmt.Sprintf("%v:%v: %v", e.File, e.Line, msg) }
+  return msg + didYouMean(e.Suggestions)
+}
+
+// Returns the error returned by the ArgChecker.
+func (e *ParseError) Unwrap() error {
+  return e.Err
+}
+
+/*
+  Returns the long option names (with "--" prepended) from usage that are
+  closest to name (which may contain "=..." which is ignored) by edit distance,
+  if that distance is small enough for name to be considered a typo.
+*/
+func (usage Usage) similarLongNames(name string) []string {
+  if eq := strings.IndexByte(name, '='); eq >= 0 { name = name[:eq] }
+  
+  longs := []string{}
+  for i := range usage {
+    if usage[i].Long == "" || usage[i].Meta().Hidden { continue }
+    longs = append(longs, usage[i].Long)
+  }
+  similar := similarNames(name, longs)
+  for i := range similar { similar[i] = "--" + similar[i] }
+  return similar
+}
+
+/*
+  Returns the elements of candidates that are closest to name by edit distance,
+  if that distance is small enough for name to be considered a typo. Duplicates
+  are returned only once. The order of candidates is preserved.
+*/
+func similarNames(name string, candidates []string) []string {
+  best := len([]rune(name))/3 + 1 // maximum distance that is considered a typo
+  if best > 3 { best = 3 }
+  similar := []string{}
+  for _, cand := range candidates {
+    d := editDistance(name, cand)
+    if d > best { continue }
+    if d < best {
+      best = d
+      similar = similar[0:0]
+    }
+    dup := false
+    for _, s := range similar { if s == cand { dup = true } }
+    if !dup { similar = append(similar, cand) }
+  }
+  return similar
+}
+
+// Returns ". Did you mean X or Y?" for suggestions X and Y or "" if there are none.
+func didYouMean(suggestions []string) string {
+  if len(suggestions) == 0 { return "" }
+  return ". Did you mean " + strings.Join(suggestions, " or ") + "?"
+}
+
+// Returns the Damerau-Levenshtein distance (optimal string alignment variant) between a and b.
+func editDistance(a, b string) int {
+  s, t := []rune(a), []rune(b)
+  // d[i][j] is the distance between s[:i] and t[:j]
+  d := make([][]int, len(s)+1)
+  for i := range d {
+    d[i] = make([]int, len(t)+1)
+    d[i][0] = i
+  }
+  for j := range d[0] { d[0][j] = j }
+  
+  for i := 1; i <= len(s); i++ {
+    for j := 1; j <= len(t); j++ {
+      cost := 1
+      if s[i-1] == t[j-1] { cost = 0 }
+      d[i][j] = d[i-1][j] + 1 // deletion
+      if d[i][j-1] + 1 < d[i][j] { d[i][j] = d[i][j-1] + 1 } // insertion
+      if d[i-1][j-1] + cost < d[i][j] { d[i][j] = d[i-1][j-1] + cost } // substitution
+      if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] && d[i-2][j-2] + 1 < d[i][j] {
+        d[i][j] = d[i-2][j-2] + 1 // transposition
+      }
+    }
+  }
+  return d[len(s)][len(t)]
+}
@@ -0,0 +1,226 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named render.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package argv
+
+import (
+         "strings"
+       )
+
+/*
+  A row of a table as returned by usage.tables(). cells[col] contains the
+  parts of column col in order, with leading and trailing spaces removed and
+  empty parts dropped.
+*/
+type tablerow struct {
+  cells [][]string
+  // true iff the row is a plain line insertion (i.e. contains no '\t' and no '\v').
+  isplain bool
+}
+
+// Returns true iff the row is a plain line insertion (i.e. contains no '\t' and no '\v').
+func (row *tablerow) plain() bool {
+  return row.isplain
+}
+
+// Returns the text of a plain row.
+func (row *tablerow) text() string {
+  if len(row.cells) == 0 || len(row.cells[0]) == 0 { return "" }
+  return row.cells[0][0]
+}
+
+/*
+  Uses LinePartIterator to split the Help texts of usage into tables,
+  rows and cells.
+*/
+func (usage Usage) tables() [][]tablerow {
+  tables := [][]tablerow{}
+  for part := usage.display().Iterate(); part.NextTable(); {
+    table := []tablerow{}
+    for part.NextRow() {
+      var row tablerow
+      numparts := 0
+      for part.NextPart() {
+        for len(row.cells) <= part.Column() { row.cells = append(row.cells, nil) }
+        numparts++
+        data := strings.TrimSpace(part.Data())
+        if data != "" {
+          row.cells[part.Column()] = append(row.cells[part.Column()], data)
+        }
+      }
+      row.isplain = (numparts == 1)
+      table = append(table, row)
+    }
+    tables = append(tables, table)
+  }
+  return tables
+}
+
+/*
+ Converts the OptionInfo.Help texts from usage into troff source for
+ use in a manual page (see man(7)). The same formatting rules as for
+ Usage.String() apply, but the layout is left to troff:
+ 
+   * Rows with multiple cells (i.e. rows containing '\t') become
+     a definition list (.TP) where all cells except the last
+     form the tag and the last cell is the indented explanation.
+     
+   * Plain line insertions (rows without '\t' and '\v') are output
+     as ordinary lines with a line break after each. An empty row
+     starts a new paragraph.
+   
+   * '\v' inside the tag is replaced by a space, inside the
+     explanation it becomes a line break.
+     
+   * Table breaks ("\f") start a new paragraph.
+   
+ The result contains no .TH and .SH requests. A complete manual page
+ can be produced like this:
+ 
+   fmt.Printf(".TH FOO 1\n.SH NAME\nfoo \\- does things\n.SH OPTIONS\n%v", usage.Troff())
+*/
+func (usage Usage) Troff() string {
+  out := []string{}
+  // starts a new paragraph unless one has just been started
+  paragraph := func() {
+    if len(out) > 0 && out[len(out)-1] != ".PP" { out = append(out, ".PP") }
+  }
+  for t, table := range usage.tables() {
+    if t > 0 { paragraph() }
+    plain := false // true if the previous row was a non-empty plain row
+    for _, row := range table {
+      if row.plain() {
+        if row.text() == "" {
+          paragraph()
+          plain = false
+        } else {
+          if plain { out = append(out, ".br") }
+          out = append(out, troffEscape(row.text()))
+          plain = true
+        }
+        continue
+      }
+      
+      plain = false
+      last := len(row.cells) - 1
+      tag := []string{}
+      for _, cell := range row.cells[0:last] {
+        if len(cell) > 0 { tag = append(tag, strings.Join(cell, " ")) }
+      }
+      out = append(out, ".TP", troffEscape(strings.Join(tag, " ")))
+      for i, p := range row.cells[last] {
+        if i > 0 { out = append(out, ".br") }
+        out = append(out, troffEscape(p))
+      }
+    }
+  }
+  return strings.Join(out, "\n") + "\n"
+}
+
+/*
+ Converts the OptionInfo.Help texts from usage into Markdown (in the
+ GitHub flavour that supports tables). The same formatting rules as for
+ Usage.String() apply, but the layout is left to the Markdown renderer:
+ 
+   * Consecutive rows with multiple cells (i.e. rows containing '\t') become
+     a table. Because the Help texts contain no column headers,
+     the table's header row is empty. All cells except the last are
+     formatted as code (they typically contain the option names).
+     The last cell of each row is put into the table's last column, so
+     that the explanations line up even if rows have different numbers
+     of cells.
+     
+   * Plain line insertions (rows without '\t' and '\v') are output
+     as ordinary lines with a hard line break after each. An empty row
+     starts a new paragraph.
+   
+   * '\v' inside a cell becomes a line break (<br>) except for code cells
+     where it's replaced by a space.
+     
+   * Table breaks ("\f") start a new paragraph (and table).
+*/
+func (usage Usage) Markdown() string {
+  out := []string{}
+  for _, table := range usage.tables() {
+    columns := 0 // number of columns of the current Markdown table (0 if none)
+    plain := false // true if the previous row was a non-empty plain row
+    for _, row := range table {
+      if row.plain() {
+        if columns > 0 || (row.text() == "" && plain) { out = append(out, "") }
+        columns = 0
+        if row.text() == "" {
+          plain = false
+        } else {
+          if plain { out[len(out)-1] += "  " } // hard line break
+          out = append(out, markdownEscape(row.text()))
+          plain = true
+        }
+        continue
+      }
+      
+      if columns == 0 {
+        if plain { out = append(out, "") }
+        plain = false
+        columns = maxColumns(table)
+        out = append(out, "|" + strings.Repeat("   |", columns), "|" + strings.Repeat("---|", columns))
+      }
+      
+      line := "|"
+      last := len(row.cells) - 1
+      for col := 0; col < columns; col++ {
+        cell := ""
+        if col < last {
+          if len(row.cells[col]) > 0 {
+            cell = "`" + strings.Replace(strings.Join(row.cells[col], " "), "`", "'", -1) + "`"
+          }
+        } else if col == columns-1 { // last cell goes into the last column
+          parts := []string{}
+          for _, p := range row.cells[last] { parts = append(parts, markdownEscape(p)) }
+          cell = strings.Join(parts, "<br>")
+        }
+        line += " " + cell + " |"
+      }
+      out = append(out, line)
+    }
+    out = append(out, "")
+  }
+  return strings.Join(out, "\n")
+}
+
+// Returns the maximum number of cells of any row in table.
+func maxColumns(table []tablerow) int {
+  m := 0
+  for _, row := range table { upmax(&m, len(row.cells)) }
+  return m
+}
+
+// Escapes characters with special meaning for troff.
+func troffEscape(s string) string {
+  s = strings.Replace(s, `\`, `\e`, -1)
+  s = strings.Replace(s, "-", `\-`, -1)
+  if s != "" && (s[0] == '.' || s[0] == '\'') { s = `\&` + s }
+  return s
+}
+
+// Escapes characters with special meaning for Markdown.
+func markdownEscape(s string) string {
+  return backslashEscape(s, "`*_[]<>|#")
+}
@@ -0,0 +1,103 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named responsefile.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package argv
+
+import (
+         "fmt"
+         "io/ioutil"
+         "path/filepath"
+       )
+
+// Where a word of an argument vector expanded by expandResponseFiles() comes from.
+type wordorigin struct {
+  index int   // index of the word (or the top-level "@path" word) in the original argument vector
+  file string // the response file containing the word ("" if the word is from the argument vector)
+  line int    // the line within file
+}
+
+/*
+  Replaces every "@path" word in args with the words from the file path.
+  See the "@file" flag of Parse(). Returns the expanded argument vector and
+  for each word in it, where it comes from.
+*/
+func expandResponseFiles(args []string) (expanded []string, origins []wordorigin, err error) {
+  for i, arg := range args {
+    origin := wordorigin{i, "", 0}
+    expanded, origins, err = expandWord(arg, origin, expanded, origins, nil)
+    if err != nil { return }
+  }
+  return
+}
+
+/*
+  Appends word (or, if it's a "@path" word, the words from path) to expanded and
+  its origin to origins. active contains the absolute paths of the response files
+  currently being expanded, for cycle detection.
+*/
+func expandWord(word string, origin wordorigin, expanded []string, origins []wordorigin, active []string) ([]string, []wordorigin, error) {
+  if len(word) < 2 || word[0] != '@' {
+    return append(expanded, word), append(origins, origin), nil
+  }
+  
+  path := word[1:]
+  fail := func(err error) error {
+    return &ParseError{Kind: BAD_RESPONSE_FILE, Index: origin.index, Word: word, File: origin.file, Line: origin.line, Err: err}
+  }
+  
+  abs, err := filepath.Abs(path)
+  if err != nil { return nil, nil, fail(err) }
+  for _, a := range active {
+    if a == abs {
+      return nil, nil, fail(fmt.Errorf("Response file %v includes itself", path))
+    }
+  }
+  
+  data, err := ioutil.ReadFile(path)
+  if err != nil { return nil, nil, fail(err) }
+  
+  words, err := splitWords(string(data), 1, nil)
+  if err != nil { return nil, nil, fail(fmt.Errorf("%v: %v", path, err)) }
+  
+  active = append(active, abs)
+  for _, w := range words {
+    expanded, origins, err = expandWord(w.text, wordorigin{origin.index, path, w.line}, expanded, origins, active)
+    if err != nil { return nil, nil, err }
+  }
+  return expanded, origins, nil
+}
+
+/*
+  If err is a *ParseError whose Index refers to an argument vector expanded
+  by expandResponseFiles(), Index, File and Line are set according to origins.
+  Returns err.
+*/
+func relocateError(err error, origins []wordorigin) error {
+  if perr, ok := err.(*ParseError); ok && origins != nil && perr.File == "" {
+    if perr.Index >= 0 && perr.Index < len(origins) {
+      o := origins[perr.Index]
+      perr.Index = o.index
+      perr.File = o.file
+      perr.Line = o.line
+    }
+  }
+  return err
+}
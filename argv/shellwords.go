@@ -0,0 +1,259 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named shellwords.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package argv
+
+import (
+         "fmt"
+         "strings"
+       )
+
+// A word produced by splitWords() and the line on which it starts.
+type shellword struct {
+  text string
+  line int
+}
+
+/*
+  Splits line into words like a POSIX shell, e.g.
+  
+    SplitCommandLine(`cp -v "my file" 'it'\''s' $HOME/`, os.Getenv)
+  
+  returns []string{"cp", "-v", "my file", "it's", "/home/user/"}.
+  The following rules apply:
+  
+    - Unquoted whitespace (space, tab, newline, carriage return) separates words.
+    - A '#' at the beginning of a word starts a comment that extends to the end of the line.
+    - Inside '...' all characters are literal.
+    - Inside "..." a backslash escapes '\', '"', '$', '`' and newline. Before
+      other characters the backslash is literal.
+    - Outside of quotes a backslash escapes the following character.
+    - A backslash followed by a newline is removed (line continuation) except
+      inside '...'.
+    - If expand is not nil, $NAME and ${NAME} outside of '...' are replaced with
+      expand(NAME). NAME consists of letters, digits and '_' and does not start
+      with a digit. A '$' that is not followed by a NAME or '{' is literal.
+      Outside of quotes the replacement is split into several words at whitespace
+      and if it is empty, it does not produce a word (unless it is part of a
+      longer word), e.g. with FOO="a b" and EMPTY="" the line
+      `x$FOO "$FOO" $EMPTY "$EMPTY"` results in "xa", "b", "a b" and "".
+      Inside "..." the replacement is used as is.
+    - If expand is nil, '$' has no special meaning.
+  
+  Other shell features such as globbing, command substitution, redirection,
+  and ${NAME:-default} are not supported and the respective characters are
+  taken literally (except for '${', which is an error if not followed by NAME and '}').
+  
+  Returns an error if a quote or '${' is not terminated.
+  The result can be turned back into a string with JoinCommandLine().
+*/
+func SplitCommandLine(line string, expand func(name string) string) ([]string, error) {
+  words, err := splitWords(line, 1, expand)
+  if err != nil { return nil, err }
+  result := make([]string, len(words))
+  for i := range words {
+    result[i] = words[i].text
+  }
+  return result, nil
+}
+
+/*
+  Returns word quoted so that SplitCommandLine() (with or without expand)
+  and a POSIX shell turn it back into word. Words that consist only of
+  letters, digits and the characters "@%+=:,./_-" are returned unchanged.
+  All other words are put into single quotes, e.g. Quote("it's") returns
+  `'it'\''s'`. The empty word is returned as "''".
+*/
+func Quote(word string) string {
+  if word == "" { return "''" }
+  
+  safe := true
+  for i := 0; i < len(word) && safe; i++ {
+    ch := word[i]
+    safe = (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') ||
+           strings.IndexByte("@%+=:,./_-", ch) >= 0
+  }
+  if safe { return word }
+  
+  return "'" + strings.Replace(word, "'", `'\''`, -1) + "'"
+}
+
+/*
+  Returns the words of args quoted with Quote() and separated by single spaces.
+  This is the inverse of SplitCommandLine(), i.e. SplitCommandLine(JoinCommandLine(args), nil)
+  returns a copy of args. The result can also be passed to a POSIX shell to
+  execute the command exactly as given by args.
+*/
+func JoinCommandLine(args []string) string {
+  quoted := make([]string, len(args))
+  for i := range args {
+    quoted[i] = Quote(args[i])
+  }
+  return strings.Join(quoted, " ")
+}
+
+/*
+  Implements SplitCommandLine(). The line numbers of the returned words start at firstline.
+*/
+func splitWords(text string, firstline int, expand func(name string) string) ([]shellword, error) {
+  words := []shellword{}
+  line := firstline
+  var word []byte
+  inword := false
+  wordline := line
+  
+  startword := func() {
+    if !inword {
+      inword = true
+      wordline = line
+      word = word[0:0]
+    }
+  }
+  
+  endword := func() {
+    if inword {
+      words = append(words, shellword{string(word), wordline})
+      inword = false
+    }
+  }
+  
+  for i := 0; i < len(text); i++ {
+    ch := text[i]
+    switch {
+      case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+        endword()
+        if ch == '\n' { line++ }
+        
+      case ch == '#' && !inword:
+        for i < len(text) && text[i] != '\n' { i++ }
+        i-- // let the loop handle the '\n'
+        
+      case ch == '\\':
+        if i+1 < len(text) && text[i+1] == '\n' { // line continuation
+          i++
+          line++
+          continue
+        }
+        startword()
+        if i+1 < len(text) { i++ }
+        word = append(word, text[i])
+        
+      case ch == '\'':
+        startword()
+        start := line
+        for i++; i < len(text) && text[i] != '\''; i++ {
+          if text[i] == '\n' { line++ }
+          word = append(word, text[i])
+        }
+        if i == len(text) {
+          return nil, fmt.Errorf("Line %v: Unterminated ' quote", start)
+        }
+        
+      case ch == '$' && expand != nil:
+        name, end, err := variableName(text, i, line)
+        if err != nil { return nil, err }
+        if name == "" {
+          startword()
+          word = append(word, ch)
+          continue
+        }
+        i = end
+        value := expand(name)
+        fields := strings.Fields(value)
+        for k := range fields {
+          if k > 0 || strings.IndexByte(" \t\n", value[0]) >= 0 { endword() }
+          startword()
+          word = append(word, fields[k]...)
+        }
+        if value != "" && strings.IndexByte(" \t\n", value[len(value)-1]) >= 0 { endword() }
+        
+      case ch == '"':
+        startword()
+        start := line
+        for i++; i < len(text) && text[i] != '"'; i++ {
+          if text[i] == '$' && expand != nil {
+            name, end, err := variableName(text, i, line)
+            if err != nil { return nil, err }
+            if name != "" {
+              i = end
+              word = append(word, expand(name)...)
+              continue
+            }
+          }
+          if text[i] == '\\' && i+1 < len(text) {
+            switch text[i+1] {
+              case '\n':
+                i++
+                line++
+                continue
+              case '\\', '"', '$', '`':
+                i++
+            }
+          }
+          if text[i] == '\n' { line++ }
+          word = append(word, text[i])
+        }
+        if i == len(text) {
+          return nil, fmt.Errorf("Line %v: Unterminated \" quote", start)
+        }
+        
+      default:
+        startword()
+        word = append(word, ch)
+    }
+  }
+  
+  endword()
+  
+  return words, nil
+}
+
+/*
+  text[i] is a '$'. If it starts a variable reference ($NAME or ${NAME}), NAME is
+  returned together with the index of the last byte of the reference.
+  Otherwise name is "". line is the line number for error messages.
+*/
+func variableName(text string, i int, line int) (name string, end int, err error) {
+  isname := func(ch byte, first bool) bool {
+    return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '_' || (!first && ch >= '0' && ch <= '9')
+  }
+  
+  if i+1 < len(text) && text[i+1] == '{' {
+    end = strings.IndexByte(text[i+2:], '}')
+    if end < 0 {
+      return "", 0, fmt.Errorf("Line %v: Unterminated ${", line)
+    }
+    end += i+2
+    name = text[i+2:end]
+    valid := name != ""
+    for k := 0; k < len(name); k++ {
+      valid = valid && isname(name[k], k == 0)
+    }
+    if !valid {
+      return "", 0, fmt.Errorf("Line %v: Bad substitution ${%v}", line, text[i+2:end])
+    }
+    return name, end, nil
+  }
+  
+  end = i+1
+  for end < len(text) && isname(text[end], end == i+1) { end++ }
+  return text[i+1:end], end-1, nil
+}
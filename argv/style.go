@@ -0,0 +1,151 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named style.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+
+package argv
+
+import (
+         "os"
+         "strings"
+       )
+
+/*
+  Escape sequences that Usage.String() uses to highlight parts of the help text.
+  Each sequence is inserted before the respective text and Reset is inserted after it.
+  A nil *Style means no highlighting.
+  
+  Option is used for option names (words starting with "-" in all columns except
+  for the last one, e.g. "-v" and "--verbose" in "  -v, \t--verbose  \tBe verbose.").
+  
+  Arg is used for argument placeholders (text in angle brackets in all columns
+  except for the last one, e.g. "<file>" in "  -o <file>, \t--output=<file>  \t...").
+  
+  Header is used for section headers (plain line insertions (see Usage.String())
+  that end in ":", e.g. "Options:") and for an upper case word ending in ":" at the
+  beginning of a plain line (e.g. "USAGE:" in "USAGE: program [options]").
+  
+  Escape sequences of the form ESC '[' ... (i.e. ANSI CSI sequences such as
+  "\033[1m") take up no space on the screen, so they are not counted in
+  LinePartIterator.ScreenLength() and ColumnWrapper never breaks a line
+  inside of them. You may use them in your Help texts, too.
+*/
+type Style struct {
+  Option string
+  Arg string
+  Header string
+  Reset string
+}
+
+// Bold option names, underlined argument placeholders and bold cyan section headers.
+var ANSI = &Style{Option:"\033[1m", Arg:"\033[4m", Header:"\033[1;36m", Reset:"\033[0m"}
+
+/*
+  The Style used by Usage.String(). The default nil means no highlighting.
+  Even if HelpStyle is non-nil, Usage.String() will not highlight anything
+  if the environment variable NO_COLOR is set to a non-empty string or
+  if os.Stdout is not a terminal. Use Usage.StyledString() if you need to
+  make that decision yourself (e.g. because you don't write to os.Stdout).
+*/
+var HelpStyle *Style = nil
+
+/*
+  Like Usage.String() but uses style (which may be nil) for highlighting,
+  regardless of HelpStyle, NO_COLOR and whether os.Stdout is a terminal.
+*/
+func (usage Usage) StyledString(style *Style) string {
+  f := defaultFormatter()
+  f.Style = style
+  return f.Format(usage)
+}
+
+/*
+  Returns HelpStyle unless highlighting is disabled because the environment
+  variable NO_COLOR is non-empty or terminal is not a terminal, in which
+  case nil is returned.
+*/
+func style_for(terminal *os.File) *Style {
+  if HelpStyle == nil || os.Getenv("NO_COLOR") != "" || terminal == nil || !isTerminal(terminal) {
+    return nil
+  }
+  return HelpStyle
+}
+
+// Returns true if f is a terminal. Unlike a check for a character device this
+// is false for /dev/null.
+func isTerminal(f *os.File) bool {
+  return TerminalWidth(f) > 0
+}
+
+/*
+  Returns data with option names and argument placeholders highlighted.
+*/
+func (s *Style) options(data string) string {
+  if s == nil { return data }
+  var out []string
+  for i := 0; i < len(data); {
+    j := i
+    switch {
+      case data[i] == '<':
+        for j < len(data) && data[j] != '>' { j++ }
+        if j < len(data) { j++ }
+        out = append(out, s.Arg, data[i:j], s.Reset)
+      case data[i] == '-' && (i == 0 || strings.IndexByte(" ,|[(", data[i-1]) >= 0):
+        for j < len(data) && strings.IndexByte(" ,|[]()<=", data[j]) < 0 { j++ }
+        out = append(out, s.Option, data[i:j], s.Reset)
+      default:
+        for j++; j < len(data) && data[j] != '<' && data[j] != '-'; j++ {}
+        out = append(out, data[i:j])
+    }
+    i = j
+  }
+  return strings.Join(out, "")
+}
+
+/*
+  Returns data (a plain line insertion) with the header (if any) highlighted.
+*/
+func (s *Style) header(data string) string {
+  if s == nil { return data }
+  trimmed := strings.TrimRight(data, " ")
+  if strings.HasSuffix(trimmed, ":") {
+    return s.Header + trimmed + s.Reset + data[len(trimmed):]
+  }
+  
+  start := len(data) - len(strings.TrimLeft(data, " "))
+  end := start
+  for end < len(data) && data[end] >= 'A' && data[end] <= 'Z' { end++ }
+  if end > start + 1 && end < len(data) && data[end] == ':' {
+    return data[:start] + s.Header + data[start:end+1] + s.Reset + data[end+1:]
+  }
+  return data
+}
+
+/*
+  If s[i:] starts with an ANSI CSI escape sequence (ESC '[' parameters final byte),
+  its length in bytes is returned. Otherwise 0 is returned.
+*/
+func escape_len(s string, i int) int {
+  if i+1 >= len(s) || s[i] != '\033' || s[i+1] != '[' { return 0 }
+  for j := i+2; j < len(s); j++ {
+    if s[j] >= 0x40 && s[j] <= 0x7E { return j+1-i }
+  }
+  return 0
+}
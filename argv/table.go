@@ -0,0 +1,160 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named table.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+
+package argv
+
+import (
+         "io"
+         "strings"
+       )
+
+// Alignments for ColumnFormat.
+const (
+  ALIGN_LEFT = iota
+  ALIGN_RIGHT
+  ALIGN_CENTER
+)
+
+/*
+  Alignment and width limits for a column of a Table.
+  The zero value means left alignment without limits.
+*/
+type ColumnFormat struct {
+  // ALIGN_LEFT, ALIGN_RIGHT or ALIGN_CENTER.
+  Align int
+  
+  /*
+    If > 0, the column is at least this many screen columns wide.
+    For the last column this replaces LastColumnMinPercent, i.e. if less
+    space is left for the last column, it is printed on its own line.
+  */
+  MinWidth int
+  
+  /*
+    If > 0, the column is at most this many screen columns wide.
+    Text in the last column is wrapped to fit. In other columns, longer
+    cells are treated like all overlong cells, i.e. the following cell
+    continues on the next line.
+  */
+  MaxWidth int
+}
+
+/*
+  A table of text cells, formatted with the same layout engine and wrapping rules
+  as Usage.String() (see there), e.g.
+  
+    t := &argv.Table{Format: []argv.ColumnFormat{{}, {Align: argv.ALIGN_RIGHT}}}
+    t.AddRow("NAME", "SIZE", "DESCRIPTION")
+    t.AddRow("foo.txt", "1234", "A text file.")
+    t.AddRow("bar", "56", "A file with a description that is too long to fit.")
+    t.WriteTo(os.Stdout)
+  
+  results in (with Width=40)
+  
+    NAME     SIZE  DESCRIPTION
+    foo.txt  1234  A text file.
+    bar        56  A file with a description
+                   that is too long to fit.
+
+  The last column of the table is the only one whose cells are wrapped.
+  A row with a single cell (in a table with more than 1 column) is a plain
+  line that spans the whole width and does not affect the column widths (e.g. a heading).
+  Within a cell '\n' (or '\v') starts a new line. '\t' and '\f' are replaced with spaces.
+*/
+type Table struct {
+  // Alignment and width limits for the individual columns. May be shorter than
+  // the number of columns (or nil) in which case the remaining columns are
+  // left-aligned without limits.
+  Format []ColumnFormat
+  
+  // Inserted between columns. If empty, 2 spaces are used.
+  Separator string
+  
+  // Number of screen columns available. If <= 0, the width is determined
+  // in the same way as for Usage.String() (or Formatter.FormatTable()).
+  Width int
+  
+  // The cells of the table, row by row.
+  Rows [][]string
+}
+
+// Appends a row with the given cells to the table.
+func (t *Table) AddRow(cells ...string) {
+  t.Rows = append(t.Rows, cells)
+}
+
+/*
+  Writes the formatted table to w. Returns the number of bytes written
+  and the first error returned by w (if any).
+*/
+func (t *Table) WriteTo(w io.Writer) (int64, error) {
+  write := &errwriter{w:w}
+  formatTables(write, t.usage(), t.layout(defaultFormatter()))
+  return write.n, write.err
+}
+
+// Returns the formatted table.
+func (t *Table) String() string {
+  return defaultFormatter().FormatTable(t)
+}
+
+// Returns a single table Usage whose Help texts are t's rows.
+func (t *Table) usage() Usage {
+  cellfix := strings.NewReplacer("\t", " ", "\f", " ", "\n", "\v")
+  usage := make(Usage, 0, len(t.Rows))
+  for _, row := range t.Rows {
+    cells := make([]string, len(row))
+    for i := range row {
+      cells[i] = cellfix.Replace(row[i])
+    }
+    usage = append(usage, OptionInfo{-1, 0, "", "", ArgUnknown, strings.Join(cells, "\t")})
+  }
+  return usage
+}
+
+// Returns the parameters for formatting t with formatTables() according to f.
+func (t *Table) layout(f *Formatter) tablelayout {
+  layout := f.layout(1)
+  layout.style = nil
+  layout.columns = t.Format
+  layout.separator = t.Separator
+  if t.Width > 0 { layout.width = line_width(t.Width, nil) }
+  if layout.separator == "" { layout.separator = "  " }
+  for _, row := range t.Rows {
+    upmax(&layout.maxcolumns, len(row))
+  }
+  return layout
+}
+
+// An io.Writer that counts the bytes written and stops writing after the first error.
+type errwriter struct {
+  w io.Writer
+  n int64
+  err error
+}
+
+func (e *errwriter) Write(b []byte) (n int, err error) {
+  if e.err != nil { return 0, e.err }
+  n, e.err = e.w.Write(b)
+  e.n += int64(n)
+  return n, e.err
+}
@@ -0,0 +1,315 @@
+/* Copyright (C) 2015 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named unicodetables.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+
+package argv
+
+/*
+ Code point ranges whose East_Asian_Width property is W (wide) or F (fullwidth)
+ according to https://www.unicode.org/Public/15.0.0/ucd/EastAsianWidth.txt .
+ Sorted and non-overlapping.
+*/
+var wide_table = []runerange{
+  {0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC}, {0x23F0, 0x23F0},
+  {0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615}, {0x2648, 0x2653}, {0x267F, 0x267F},
+  {0x2693, 0x2693}, {0x26A1, 0x26A1}, {0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5},
+  {0x26CE, 0x26CE}, {0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+  {0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B}, {0x2728, 0x2728},
+  {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+  {0x27B0, 0x27B0}, {0x27BF, 0x27BF}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55},
+  {0x2E80, 0x2E99}, {0x2E9B, 0x2EF3}, {0x2F00, 0x2FD5}, {0x2FF0, 0x2FFB}, {0x3000, 0x303E},
+  {0x3041, 0x3096}, {0x3099, 0x30FF}, {0x3105, 0x312F}, {0x3131, 0x318E}, {0x3190, 0x31E3},
+  {0x31F0, 0x321E}, {0x3220, 0x3247}, {0x3250, 0x4DBF}, {0x4E00, 0xA48C}, {0xA490, 0xA4C6},
+  {0xA960, 0xA97C}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19}, {0xFE30, 0xFE52},
+  {0xFE54, 0xFE66}, {0xFE68, 0xFE6B}, {0xFF01, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x16FE4},
+  {0x16FF0, 0x16FF1}, {0x17000, 0x187F7}, {0x18800, 0x18CD5}, {0x18D00, 0x18D08},
+  {0x1AFF0, 0x1AFF3}, {0x1AFF5, 0x1AFFB}, {0x1AFFD, 0x1AFFE}, {0x1B000, 0x1B122},
+  {0x1B132, 0x1B132}, {0x1B150, 0x1B152}, {0x1B155, 0x1B155}, {0x1B164, 0x1B167},
+  {0x1B170, 0x1B2FB}, {0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF}, {0x1F18E, 0x1F18E},
+  {0x1F191, 0x1F19A}, {0x1F200, 0x1F202}, {0x1F210, 0x1F23B}, {0x1F240, 0x1F248},
+  {0x1F250, 0x1F251}, {0x1F260, 0x1F265}, {0x1F300, 0x1F320}, {0x1F32D, 0x1F335},
+  {0x1F337, 0x1F37C}, {0x1F37E, 0x1F393}, {0x1F3A0, 0x1F3CA}, {0x1F3CF, 0x1F3D3},
+  {0x1F3E0, 0x1F3F0}, {0x1F3F4, 0x1F3F4}, {0x1F3F8, 0x1F43E}, {0x1F440, 0x1F440},
+  {0x1F442, 0x1F4FC}, {0x1F4FF, 0x1F53D}, {0x1F54B, 0x1F54E}, {0x1F550, 0x1F567},
+  {0x1F57A, 0x1F57A}, {0x1F595, 0x1F596}, {0x1F5A4, 0x1F5A4}, {0x1F5FB, 0x1F64F},
+  {0x1F680, 0x1F6C5}, {0x1F6CC, 0x1F6CC}, {0x1F6D0, 0x1F6D2}, {0x1F6D5, 0x1F6D7},
+  {0x1F6DC, 0x1F6DF}, {0x1F6EB, 0x1F6EC}, {0x1F6F4, 0x1F6FC}, {0x1F7E0, 0x1F7EB},
+  {0x1F7F0, 0x1F7F0}, {0x1F90C, 0x1F93A}, {0x1F93C, 0x1F945}, {0x1F947, 0x1F9FF},
+  {0x1FA70, 0x1FA7C}, {0x1FA80, 0x1FA88}, {0x1FA90, 0x1FABD}, {0x1FABF, 0x1FAC5},
+  {0x1FACE, 0x1FADB}, {0x1FAE0, 0x1FAE8}, {0x1FAF0, 0x1FAF8}, {0x20000, 0x2FFFD},
+  {0x30000, 0x3FFFD},
+}
+
+/*
+ Grapheme_Cluster_Break properties according to
+ https://www.unicode.org/Public/15.0.0/ucd/auxiliary/GraphemeBreakProperty.txt
+ and the Extended_Pictographic property according to
+ https://www.unicode.org/Public/15.0.0/ucd/emoji/emoji-data.txt .
+ Hangul syllables (LV and LVT) are not listed because they are computed
+ by grapheme_property(). Code points not listed have the property gb_other.
+ Sorted and non-overlapping.
+*/
+var grapheme_table = []propertyrange{
+  {0x0000, 0x0009, gb_control}, {0x000A, 0x000A, gb_lf}, {0x000B, 0x000C, gb_control},
+  {0x000D, 0x000D, gb_cr}, {0x000E, 0x001F, gb_control}, {0x007F, 0x009F, gb_control},
+  {0x00A9, 0x00A9, gb_pictographic}, {0x00AD, 0x00AD, gb_control},
+  {0x00AE, 0x00AE, gb_pictographic}, {0x0300, 0x036F, gb_extend}, {0x0483, 0x0489, gb_extend},
+  {0x0591, 0x05BD, gb_extend}, {0x05BF, 0x05BF, gb_extend}, {0x05C1, 0x05C2, gb_extend},
+  {0x05C4, 0x05C5, gb_extend}, {0x05C7, 0x05C7, gb_extend}, {0x0600, 0x0605, gb_prepend},
+  {0x0610, 0x061A, gb_extend}, {0x061C, 0x061C, gb_control}, {0x064B, 0x065F, gb_extend},
+  {0x0670, 0x0670, gb_extend}, {0x06D6, 0x06DC, gb_extend}, {0x06DD, 0x06DD, gb_prepend},
+  {0x06DF, 0x06E4, gb_extend}, {0x06E7, 0x06E8, gb_extend}, {0x06EA, 0x06ED, gb_extend},
+  {0x070F, 0x070F, gb_prepend}, {0x0711, 0x0711, gb_extend}, {0x0730, 0x074A, gb_extend},
+  {0x07A6, 0x07B0, gb_extend}, {0x07EB, 0x07F3, gb_extend}, {0x07FD, 0x07FD, gb_extend},
+  {0x0816, 0x0819, gb_extend}, {0x081B, 0x0823, gb_extend}, {0x0825, 0x0827, gb_extend},
+  {0x0829, 0x082D, gb_extend}, {0x0859, 0x085B, gb_extend}, {0x0890, 0x0891, gb_prepend},
+  {0x0898, 0x089F, gb_extend}, {0x08CA, 0x08E1, gb_extend}, {0x08E2, 0x08E2, gb_prepend},
+  {0x08E3, 0x0902, gb_extend}, {0x0903, 0x0903, gb_spacingmark}, {0x093A, 0x093A, gb_extend},
+  {0x093B, 0x093B, gb_spacingmark}, {0x093C, 0x093C, gb_extend}, {0x093E, 0x0940, gb_spacingmark},
+  {0x0941, 0x0948, gb_extend}, {0x0949, 0x094C, gb_spacingmark}, {0x094D, 0x094D, gb_extend},
+  {0x094E, 0x094F, gb_spacingmark}, {0x0951, 0x0957, gb_extend}, {0x0962, 0x0963, gb_extend},
+  {0x0981, 0x0981, gb_extend}, {0x0982, 0x0983, gb_spacingmark}, {0x09BC, 0x09BC, gb_extend},
+  {0x09BE, 0x09BE, gb_extend}, {0x09BF, 0x09C0, gb_spacingmark}, {0x09C1, 0x09C4, gb_extend},
+  {0x09C7, 0x09C8, gb_spacingmark}, {0x09CB, 0x09CC, gb_spacingmark}, {0x09CD, 0x09CD, gb_extend},
+  {0x09D7, 0x09D7, gb_extend}, {0x09E2, 0x09E3, gb_extend}, {0x09FE, 0x09FE, gb_extend},
+  {0x0A01, 0x0A02, gb_extend}, {0x0A03, 0x0A03, gb_spacingmark}, {0x0A3C, 0x0A3C, gb_extend},
+  {0x0A3E, 0x0A40, gb_spacingmark}, {0x0A41, 0x0A42, gb_extend}, {0x0A47, 0x0A48, gb_extend},
+  {0x0A4B, 0x0A4D, gb_extend}, {0x0A51, 0x0A51, gb_extend}, {0x0A70, 0x0A71, gb_extend},
+  {0x0A75, 0x0A75, gb_extend}, {0x0A81, 0x0A82, gb_extend}, {0x0A83, 0x0A83, gb_spacingmark},
+  {0x0ABC, 0x0ABC, gb_extend}, {0x0ABE, 0x0AC0, gb_spacingmark}, {0x0AC1, 0x0AC5, gb_extend},
+  {0x0AC7, 0x0AC8, gb_extend}, {0x0AC9, 0x0AC9, gb_spacingmark}, {0x0ACB, 0x0ACC, gb_spacingmark},
+  {0x0ACD, 0x0ACD, gb_extend}, {0x0AE2, 0x0AE3, gb_extend}, {0x0AFA, 0x0AFF, gb_extend},
+  {0x0B01, 0x0B01, gb_extend}, {0x0B02, 0x0B03, gb_spacingmark}, {0x0B3C, 0x0B3C, gb_extend},
+  {0x0B3E, 0x0B3F, gb_extend}, {0x0B40, 0x0B40, gb_spacingmark}, {0x0B41, 0x0B44, gb_extend},
+  {0x0B47, 0x0B48, gb_spacingmark}, {0x0B4B, 0x0B4C, gb_spacingmark}, {0x0B4D, 0x0B4D, gb_extend},
+  {0x0B55, 0x0B57, gb_extend}, {0x0B62, 0x0B63, gb_extend}, {0x0B82, 0x0B82, gb_extend},
+  {0x0BBE, 0x0BBE, gb_extend}, {0x0BBF, 0x0BBF, gb_spacingmark}, {0x0BC0, 0x0BC0, gb_extend},
+  {0x0BC1, 0x0BC2, gb_spacingmark}, {0x0BC6, 0x0BC8, gb_spacingmark},
+  {0x0BCA, 0x0BCC, gb_spacingmark}, {0x0BCD, 0x0BCD, gb_extend}, {0x0BD7, 0x0BD7, gb_extend},
+  {0x0C00, 0x0C00, gb_extend}, {0x0C01, 0x0C03, gb_spacingmark}, {0x0C04, 0x0C04, gb_extend},
+  {0x0C3C, 0x0C3C, gb_extend}, {0x0C3E, 0x0C40, gb_extend}, {0x0C41, 0x0C44, gb_spacingmark},
+  {0x0C46, 0x0C48, gb_extend}, {0x0C4A, 0x0C4D, gb_extend}, {0x0C55, 0x0C56, gb_extend},
+  {0x0C62, 0x0C63, gb_extend}, {0x0C81, 0x0C81, gb_extend}, {0x0C82, 0x0C83, gb_spacingmark},
+  {0x0CBC, 0x0CBC, gb_extend}, {0x0CBE, 0x0CBE, gb_spacingmark}, {0x0CBF, 0x0CBF, gb_extend},
+  {0x0CC0, 0x0CC1, gb_spacingmark}, {0x0CC2, 0x0CC2, gb_extend}, {0x0CC3, 0x0CC4, gb_spacingmark},
+  {0x0CC6, 0x0CC6, gb_extend}, {0x0CC7, 0x0CC8, gb_spacingmark}, {0x0CCA, 0x0CCB, gb_spacingmark},
+  {0x0CCC, 0x0CCD, gb_extend}, {0x0CD5, 0x0CD6, gb_extend}, {0x0CE2, 0x0CE3, gb_extend},
+  {0x0CF3, 0x0CF3, gb_spacingmark}, {0x0D00, 0x0D01, gb_extend}, {0x0D02, 0x0D03, gb_spacingmark},
+  {0x0D3B, 0x0D3C, gb_extend}, {0x0D3E, 0x0D3E, gb_extend}, {0x0D3F, 0x0D40, gb_spacingmark},
+  {0x0D41, 0x0D44, gb_extend}, {0x0D46, 0x0D48, gb_spacingmark}, {0x0D4A, 0x0D4C, gb_spacingmark},
+  {0x0D4D, 0x0D4D, gb_extend}, {0x0D4E, 0x0D4E, gb_prepend}, {0x0D57, 0x0D57, gb_extend},
+  {0x0D62, 0x0D63, gb_extend}, {0x0D81, 0x0D81, gb_extend}, {0x0D82, 0x0D83, gb_spacingmark},
+  {0x0DCA, 0x0DCA, gb_extend}, {0x0DCF, 0x0DCF, gb_extend}, {0x0DD0, 0x0DD1, gb_spacingmark},
+  {0x0DD2, 0x0DD4, gb_extend}, {0x0DD6, 0x0DD6, gb_extend}, {0x0DD8, 0x0DDE, gb_spacingmark},
+  {0x0DDF, 0x0DDF, gb_extend}, {0x0DF2, 0x0DF3, gb_spacingmark}, {0x0E31, 0x0E31, gb_extend},
+  {0x0E33, 0x0E33, gb_spacingmark}, {0x0E34, 0x0E3A, gb_extend}, {0x0E47, 0x0E4E, gb_extend},
+  {0x0EB1, 0x0EB1, gb_extend}, {0x0EB3, 0x0EB3, gb_spacingmark}, {0x0EB4, 0x0EBC, gb_extend},
+  {0x0EC8, 0x0ECE, gb_extend}, {0x0F18, 0x0F19, gb_extend}, {0x0F35, 0x0F35, gb_extend},
+  {0x0F37, 0x0F37, gb_extend}, {0x0F39, 0x0F39, gb_extend}, {0x0F3E, 0x0F3F, gb_spacingmark},
+  {0x0F71, 0x0F7E, gb_extend}, {0x0F7F, 0x0F7F, gb_spacingmark}, {0x0F80, 0x0F84, gb_extend},
+  {0x0F86, 0x0F87, gb_extend}, {0x0F8D, 0x0F97, gb_extend}, {0x0F99, 0x0FBC, gb_extend},
+  {0x0FC6, 0x0FC6, gb_extend}, {0x102D, 0x1030, gb_extend}, {0x1031, 0x1031, gb_spacingmark},
+  {0x1032, 0x1037, gb_extend}, {0x1039, 0x103A, gb_extend}, {0x103B, 0x103C, gb_spacingmark},
+  {0x103D, 0x103E, gb_extend}, {0x1056, 0x1057, gb_spacingmark}, {0x1058, 0x1059, gb_extend},
+  {0x105E, 0x1060, gb_extend}, {0x1071, 0x1074, gb_extend}, {0x1082, 0x1082, gb_extend},
+  {0x1084, 0x1084, gb_spacingmark}, {0x1085, 0x1086, gb_extend}, {0x108D, 0x108D, gb_extend},
+  {0x109D, 0x109D, gb_extend}, {0x1100, 0x115F, gb_l}, {0x1160, 0x11A7, gb_v},
+  {0x11A8, 0x11FF, gb_t}, {0x135D, 0x135F, gb_extend}, {0x1712, 0x1714, gb_extend},
+  {0x1715, 0x1715, gb_spacingmark}, {0x1732, 0x1733, gb_extend}, {0x1734, 0x1734, gb_spacingmark},
+  {0x1752, 0x1753, gb_extend}, {0x1772, 0x1773, gb_extend}, {0x17B4, 0x17B5, gb_extend},
+  {0x17B6, 0x17B6, gb_spacingmark}, {0x17B7, 0x17BD, gb_extend}, {0x17BE, 0x17C5, gb_spacingmark},
+  {0x17C6, 0x17C6, gb_extend}, {0x17C7, 0x17C8, gb_spacingmark}, {0x17C9, 0x17D3, gb_extend},
+  {0x17DD, 0x17DD, gb_extend}, {0x180B, 0x180D, gb_extend}, {0x180E, 0x180E, gb_control},
+  {0x180F, 0x180F, gb_extend}, {0x1885, 0x1886, gb_extend}, {0x18A9, 0x18A9, gb_extend},
+  {0x1920, 0x1922, gb_extend}, {0x1923, 0x1926, gb_spacingmark}, {0x1927, 0x1928, gb_extend},
+  {0x1929, 0x192B, gb_spacingmark}, {0x1930, 0x1931, gb_spacingmark}, {0x1932, 0x1932, gb_extend},
+  {0x1933, 0x1938, gb_spacingmark}, {0x1939, 0x193B, gb_extend}, {0x1A17, 0x1A18, gb_extend},
+  {0x1A19, 0x1A1A, gb_spacingmark}, {0x1A1B, 0x1A1B, gb_extend}, {0x1A55, 0x1A55, gb_spacingmark},
+  {0x1A56, 0x1A56, gb_extend}, {0x1A57, 0x1A57, gb_spacingmark}, {0x1A58, 0x1A5E, gb_extend},
+  {0x1A60, 0x1A60, gb_extend}, {0x1A62, 0x1A62, gb_extend}, {0x1A65, 0x1A6C, gb_extend},
+  {0x1A6D, 0x1A72, gb_spacingmark}, {0x1A73, 0x1A7C, gb_extend}, {0x1A7F, 0x1A7F, gb_extend},
+  {0x1AB0, 0x1ACE, gb_extend}, {0x1B00, 0x1B03, gb_extend}, {0x1B04, 0x1B04, gb_spacingmark},
+  {0x1B34, 0x1B3A, gb_extend}, {0x1B3B, 0x1B3B, gb_spacingmark}, {0x1B3C, 0x1B3C, gb_extend},
+  {0x1B3D, 0x1B41, gb_spacingmark}, {0x1B42, 0x1B42, gb_extend}, {0x1B43, 0x1B44, gb_spacingmark},
+  {0x1B6B, 0x1B73, gb_extend}, {0x1B80, 0x1B81, gb_extend}, {0x1B82, 0x1B82, gb_spacingmark},
+  {0x1BA1, 0x1BA1, gb_spacingmark}, {0x1BA2, 0x1BA5, gb_extend}, {0x1BA6, 0x1BA7, gb_spacingmark},
+  {0x1BA8, 0x1BA9, gb_extend}, {0x1BAA, 0x1BAA, gb_spacingmark}, {0x1BAB, 0x1BAD, gb_extend},
+  {0x1BE6, 0x1BE6, gb_extend}, {0x1BE7, 0x1BE7, gb_spacingmark}, {0x1BE8, 0x1BE9, gb_extend},
+  {0x1BEA, 0x1BEC, gb_spacingmark}, {0x1BED, 0x1BED, gb_extend}, {0x1BEE, 0x1BEE, gb_spacingmark},
+  {0x1BEF, 0x1BF1, gb_extend}, {0x1BF2, 0x1BF3, gb_spacingmark}, {0x1C24, 0x1C2B, gb_spacingmark},
+  {0x1C2C, 0x1C33, gb_extend}, {0x1C34, 0x1C35, gb_spacingmark}, {0x1C36, 0x1C37, gb_extend},
+  {0x1CD0, 0x1CD2, gb_extend}, {0x1CD4, 0x1CE0, gb_extend}, {0x1CE1, 0x1CE1, gb_spacingmark},
+  {0x1CE2, 0x1CE8, gb_extend}, {0x1CED, 0x1CED, gb_extend}, {0x1CF4, 0x1CF4, gb_extend},
+  {0x1CF7, 0x1CF7, gb_spacingmark}, {0x1CF8, 0x1CF9, gb_extend}, {0x1DC0, 0x1DFF, gb_extend},
+  {0x200B, 0x200B, gb_control}, {0x200C, 0x200C, gb_extend}, {0x200D, 0x200D, gb_zwj},
+  {0x200E, 0x200F, gb_control}, {0x2028, 0x202E, gb_control}, {0x203C, 0x203C, gb_pictographic},
+  {0x2049, 0x2049, gb_pictographic}, {0x2060, 0x206F, gb_control}, {0x20D0, 0x20F0, gb_extend},
+  {0x2122, 0x2122, gb_pictographic}, {0x2139, 0x2139, gb_pictographic},
+  {0x2194, 0x2199, gb_pictographic}, {0x21A9, 0x21AA, gb_pictographic},
+  {0x231A, 0x231B, gb_pictographic}, {0x2328, 0x2328, gb_pictographic},
+  {0x2388, 0x2388, gb_pictographic}, {0x23CF, 0x23CF, gb_pictographic},
+  {0x23E9, 0x23F3, gb_pictographic}, {0x23F8, 0x23FA, gb_pictographic},
+  {0x24C2, 0x24C2, gb_pictographic}, {0x25AA, 0x25AB, gb_pictographic},
+  {0x25B6, 0x25B6, gb_pictographic}, {0x25C0, 0x25C0, gb_pictographic},
+  {0x25FB, 0x25FE, gb_pictographic}, {0x2600, 0x2605, gb_pictographic},
+  {0x2607, 0x2612, gb_pictographic}, {0x2614, 0x2685, gb_pictographic},
+  {0x2690, 0x2705, gb_pictographic}, {0x2708, 0x2712, gb_pictographic},
+  {0x2714, 0x2714, gb_pictographic}, {0x2716, 0x2716, gb_pictographic},
+  {0x271D, 0x271D, gb_pictographic}, {0x2721, 0x2721, gb_pictographic},
+  {0x2728, 0x2728, gb_pictographic}, {0x2733, 0x2734, gb_pictographic},
+  {0x2744, 0x2744, gb_pictographic}, {0x2747, 0x2747, gb_pictographic},
+  {0x274C, 0x274C, gb_pictographic}, {0x274E, 0x274E, gb_pictographic},
+  {0x2753, 0x2755, gb_pictographic}, {0x2757, 0x2757, gb_pictographic},
+  {0x2763, 0x2767, gb_pictographic}, {0x2795, 0x2797, gb_pictographic},
+  {0x27A1, 0x27A1, gb_pictographic}, {0x27B0, 0x27B0, gb_pictographic},
+  {0x27BF, 0x27BF, gb_pictographic}, {0x2934, 0x2935, gb_pictographic},
+  {0x2B05, 0x2B07, gb_pictographic}, {0x2B1B, 0x2B1C, gb_pictographic},
+  {0x2B50, 0x2B50, gb_pictographic}, {0x2B55, 0x2B55, gb_pictographic},
+  {0x2CEF, 0x2CF1, gb_extend}, {0x2D7F, 0x2D7F, gb_extend}, {0x2DE0, 0x2DFF, gb_extend},
+  {0x302A, 0x302F, gb_extend}, {0x3030, 0x3030, gb_pictographic},
+  {0x303D, 0x303D, gb_pictographic}, {0x3099, 0x309A, gb_extend},
+  {0x3297, 0x3297, gb_pictographic}, {0x3299, 0x3299, gb_pictographic},
+  {0xA66F, 0xA672, gb_extend}, {0xA674, 0xA67D, gb_extend}, {0xA69E, 0xA69F, gb_extend},
+  {0xA6F0, 0xA6F1, gb_extend}, {0xA802, 0xA802, gb_extend}, {0xA806, 0xA806, gb_extend},
+  {0xA80B, 0xA80B, gb_extend}, {0xA823, 0xA824, gb_spacingmark}, {0xA825, 0xA826, gb_extend},
+  {0xA827, 0xA827, gb_spacingmark}, {0xA82C, 0xA82C, gb_extend}, {0xA880, 0xA881, gb_spacingmark},
+  {0xA8B4, 0xA8C3, gb_spacingmark}, {0xA8C4, 0xA8C5, gb_extend}, {0xA8E0, 0xA8F1, gb_extend},
+  {0xA8FF, 0xA8FF, gb_extend}, {0xA926, 0xA92D, gb_extend}, {0xA947, 0xA951, gb_extend},
+  {0xA952, 0xA953, gb_spacingmark}, {0xA960, 0xA97C, gb_l}, {0xA980, 0xA982, gb_extend},
+  {0xA983, 0xA983, gb_spacingmark}, {0xA9B3, 0xA9B3, gb_extend}, {0xA9B4, 0xA9B5, gb_spacingmark},
+  {0xA9B6, 0xA9B9, gb_extend}, {0xA9BA, 0xA9BB, gb_spacingmark}, {0xA9BC, 0xA9BD, gb_extend},
+  {0xA9BE, 0xA9C0, gb_spacingmark}, {0xA9E5, 0xA9E5, gb_extend}, {0xAA29, 0xAA2E, gb_extend},
+  {0xAA2F, 0xAA30, gb_spacingmark}, {0xAA31, 0xAA32, gb_extend}, {0xAA33, 0xAA34, gb_spacingmark},
+  {0xAA35, 0xAA36, gb_extend}, {0xAA43, 0xAA43, gb_extend}, {0xAA4C, 0xAA4C, gb_extend},
+  {0xAA4D, 0xAA4D, gb_spacingmark}, {0xAA7C, 0xAA7C, gb_extend}, {0xAAB0, 0xAAB0, gb_extend},
+  {0xAAB2, 0xAAB4, gb_extend}, {0xAAB7, 0xAAB8, gb_extend}, {0xAABE, 0xAABF, gb_extend},
+  {0xAAC1, 0xAAC1, gb_extend}, {0xAAEB, 0xAAEB, gb_spacingmark}, {0xAAEC, 0xAAED, gb_extend},
+  {0xAAEE, 0xAAEF, gb_spacingmark}, {0xAAF5, 0xAAF5, gb_spacingmark}, {0xAAF6, 0xAAF6, gb_extend},
+  {0xABE3, 0xABE4, gb_spacingmark}, {0xABE5, 0xABE5, gb_extend}, {0xABE6, 0xABE7, gb_spacingmark},
+  {0xABE8, 0xABE8, gb_extend}, {0xABE9, 0xABEA, gb_spacingmark}, {0xABEC, 0xABEC, gb_spacingmark},
+  {0xABED, 0xABED, gb_extend}, {0xD7B0, 0xD7C6, gb_v}, {0xD7CB, 0xD7FB, gb_t},
+  {0xFB1E, 0xFB1E, gb_extend}, {0xFE00, 0xFE0F, gb_extend}, {0xFE20, 0xFE2F, gb_extend},
+  {0xFEFF, 0xFEFF, gb_control}, {0xFF9E, 0xFF9F, gb_extend}, {0xFFF0, 0xFFFB, gb_control},
+  {0x101FD, 0x101FD, gb_extend}, {0x102E0, 0x102E0, gb_extend}, {0x10376, 0x1037A, gb_extend},
+  {0x10A01, 0x10A03, gb_extend}, {0x10A05, 0x10A06, gb_extend}, {0x10A0C, 0x10A0F, gb_extend},
+  {0x10A38, 0x10A3A, gb_extend}, {0x10A3F, 0x10A3F, gb_extend}, {0x10AE5, 0x10AE6, gb_extend},
+  {0x10D24, 0x10D27, gb_extend}, {0x10EAB, 0x10EAC, gb_extend}, {0x10EFD, 0x10EFF, gb_extend},
+  {0x10F46, 0x10F50, gb_extend}, {0x10F82, 0x10F85, gb_extend}, {0x11000, 0x11000, gb_spacingmark},
+  {0x11001, 0x11001, gb_extend}, {0x11002, 0x11002, gb_spacingmark}, {0x11038, 0x11046, gb_extend},
+  {0x11070, 0x11070, gb_extend}, {0x11073, 0x11074, gb_extend}, {0x1107F, 0x11081, gb_extend},
+  {0x11082, 0x11082, gb_spacingmark}, {0x110B0, 0x110B2, gb_spacingmark},
+  {0x110B3, 0x110B6, gb_extend}, {0x110B7, 0x110B8, gb_spacingmark}, {0x110B9, 0x110BA, gb_extend},
+  {0x110BD, 0x110BD, gb_prepend}, {0x110C2, 0x110C2, gb_extend}, {0x110CD, 0x110CD, gb_prepend},
+  {0x11100, 0x11102, gb_extend}, {0x11127, 0x1112B, gb_extend}, {0x1112C, 0x1112C, gb_spacingmark},
+  {0x1112D, 0x11134, gb_extend}, {0x11145, 0x11146, gb_spacingmark}, {0x11173, 0x11173, gb_extend},
+  {0x11180, 0x11181, gb_extend}, {0x11182, 0x11182, gb_spacingmark},
+  {0x111B3, 0x111B5, gb_spacingmark}, {0x111B6, 0x111BE, gb_extend},
+  {0x111BF, 0x111C0, gb_spacingmark}, {0x111C2, 0x111C3, gb_prepend},
+  {0x111C9, 0x111CC, gb_extend}, {0x111CE, 0x111CE, gb_spacingmark}, {0x111CF, 0x111CF, gb_extend},
+  {0x1122C, 0x1122E, gb_spacingmark}, {0x1122F, 0x11231, gb_extend},
+  {0x11232, 0x11233, gb_spacingmark}, {0x11234, 0x11234, gb_extend},
+  {0x11235, 0x11235, gb_spacingmark}, {0x11236, 0x11237, gb_extend}, {0x1123E, 0x1123E, gb_extend},
+  {0x11241, 0x11241, gb_extend}, {0x112DF, 0x112DF, gb_extend}, {0x112E0, 0x112E2, gb_spacingmark},
+  {0x112E3, 0x112EA, gb_extend}, {0x11300, 0x11301, gb_extend}, {0x11302, 0x11303, gb_spacingmark},
+  {0x1133B, 0x1133C, gb_extend}, {0x1133E, 0x1133E, gb_extend}, {0x1133F, 0x1133F, gb_spacingmark},
+  {0x11340, 0x11340, gb_extend}, {0x11341, 0x11344, gb_spacingmark},
+  {0x11347, 0x11348, gb_spacingmark}, {0x1134B, 0x1134D, gb_spacingmark},
+  {0x11357, 0x11357, gb_extend}, {0x11362, 0x11363, gb_spacingmark}, {0x11366, 0x1136C, gb_extend},
+  {0x11370, 0x11374, gb_extend}, {0x11435, 0x11437, gb_spacingmark}, {0x11438, 0x1143F, gb_extend},
+  {0x11440, 0x11441, gb_spacingmark}, {0x11442, 0x11444, gb_extend},
+  {0x11445, 0x11445, gb_spacingmark}, {0x11446, 0x11446, gb_extend}, {0x1145E, 0x1145E, gb_extend},
+  {0x114B0, 0x114B0, gb_extend}, {0x114B1, 0x114B2, gb_spacingmark}, {0x114B3, 0x114B8, gb_extend},
+  {0x114B9, 0x114B9, gb_spacingmark}, {0x114BA, 0x114BA, gb_extend},
+  {0x114BB, 0x114BC, gb_spacingmark}, {0x114BD, 0x114BD, gb_extend},
+  {0x114BE, 0x114BE, gb_spacingmark}, {0x114BF, 0x114C0, gb_extend},
+  {0x114C1, 0x114C1, gb_spacingmark}, {0x114C2, 0x114C3, gb_extend}, {0x115AF, 0x115AF, gb_extend},
+  {0x115B0, 0x115B1, gb_spacingmark}, {0x115B2, 0x115B5, gb_extend},
+  {0x115B8, 0x115BB, gb_spacingmark}, {0x115BC, 0x115BD, gb_extend},
+  {0x115BE, 0x115BE, gb_spacingmark}, {0x115BF, 0x115C0, gb_extend}, {0x115DC, 0x115DD, gb_extend},
+  {0x11630, 0x11632, gb_spacingmark}, {0x11633, 0x1163A, gb_extend},
+  {0x1163B, 0x1163C, gb_spacingmark}, {0x1163D, 0x1163D, gb_extend},
+  {0x1163E, 0x1163E, gb_spacingmark}, {0x1163F, 0x11640, gb_extend}, {0x116AB, 0x116AB, gb_extend},
+  {0x116AC, 0x116AC, gb_spacingmark}, {0x116AD, 0x116AD, gb_extend},
+  {0x116AE, 0x116AF, gb_spacingmark}, {0x116B0, 0x116B5, gb_extend},
+  {0x116B6, 0x116B6, gb_spacingmark}, {0x116B7, 0x116B7, gb_extend}, {0x1171D, 0x1171F, gb_extend},
+  {0x11722, 0x11725, gb_extend}, {0x11726, 0x11726, gb_spacingmark}, {0x11727, 0x1172B, gb_extend},
+  {0x1182C, 0x1182E, gb_spacingmark}, {0x1182F, 0x11837, gb_extend},
+  {0x11838, 0x11838, gb_spacingmark}, {0x11839, 0x1183A, gb_extend}, {0x11930, 0x11930, gb_extend},
+  {0x11931, 0x11935, gb_spacingmark}, {0x11937, 0x11938, gb_spacingmark},
+  {0x1193B, 0x1193C, gb_extend}, {0x1193D, 0x1193D, gb_spacingmark}, {0x1193E, 0x1193E, gb_extend},
+  {0x1193F, 0x1193F, gb_prepend}, {0x11940, 0x11940, gb_spacingmark},
+  {0x11941, 0x11941, gb_prepend}, {0x11942, 0x11942, gb_spacingmark},
+  {0x11943, 0x11943, gb_extend}, {0x119D1, 0x119D3, gb_spacingmark}, {0x119D4, 0x119D7, gb_extend},
+  {0x119DA, 0x119DB, gb_extend}, {0x119DC, 0x119DF, gb_spacingmark}, {0x119E0, 0x119E0, gb_extend},
+  {0x119E4, 0x119E4, gb_spacingmark}, {0x11A01, 0x11A0A, gb_extend}, {0x11A33, 0x11A38, gb_extend},
+  {0x11A39, 0x11A39, gb_spacingmark}, {0x11A3A, 0x11A3A, gb_prepend},
+  {0x11A3B, 0x11A3E, gb_extend}, {0x11A47, 0x11A47, gb_extend}, {0x11A51, 0x11A56, gb_extend},
+  {0x11A57, 0x11A58, gb_spacingmark}, {0x11A59, 0x11A5B, gb_extend},
+  {0x11A84, 0x11A89, gb_prepend}, {0x11A8A, 0x11A96, gb_extend},
+  {0x11A97, 0x11A97, gb_spacingmark}, {0x11A98, 0x11A99, gb_extend},
+  {0x11C2F, 0x11C2F, gb_spacingmark}, {0x11C30, 0x11C36, gb_extend}, {0x11C38, 0x11C3D, gb_extend},
+  {0x11C3E, 0x11C3E, gb_spacingmark}, {0x11C3F, 0x11C3F, gb_extend}, {0x11C92, 0x11CA7, gb_extend},
+  {0x11CA9, 0x11CA9, gb_spacingmark}, {0x11CAA, 0x11CB0, gb_extend},
+  {0x11CB1, 0x11CB1, gb_spacingmark}, {0x11CB2, 0x11CB3, gb_extend},
+  {0x11CB4, 0x11CB4, gb_spacingmark}, {0x11CB5, 0x11CB6, gb_extend}, {0x11D31, 0x11D36, gb_extend},
+  {0x11D3A, 0x11D3A, gb_extend}, {0x11D3C, 0x11D3D, gb_extend}, {0x11D3F, 0x11D45, gb_extend},
+  {0x11D46, 0x11D46, gb_prepend}, {0x11D47, 0x11D47, gb_extend},
+  {0x11D8A, 0x11D8E, gb_spacingmark}, {0x11D90, 0x11D91, gb_extend},
+  {0x11D93, 0x11D94, gb_spacingmark}, {0x11D95, 0x11D95, gb_extend},
+  {0x11D96, 0x11D96, gb_spacingmark}, {0x11D97, 0x11D97, gb_extend}, {0x11EF3, 0x11EF4, gb_extend},
+  {0x11EF5, 0x11EF6, gb_spacingmark}, {0x11F00, 0x11F01, gb_extend},
+  {0x11F02, 0x11F02, gb_prepend}, {0x11F03, 0x11F03, gb_spacingmark},
+  {0x11F34, 0x11F35, gb_spacingmark}, {0x11F36, 0x11F3A, gb_extend},
+  {0x11F3E, 0x11F3F, gb_spacingmark}, {0x11F40, 0x11F40, gb_extend},
+  {0x11F41, 0x11F41, gb_spacingmark}, {0x11F42, 0x11F42, gb_extend},
+  {0x13430, 0x1343F, gb_control}, {0x13440, 0x13440, gb_extend}, {0x13447, 0x13455, gb_extend},
+  {0x16AF0, 0x16AF4, gb_extend}, {0x16B30, 0x16B36, gb_extend}, {0x16F4F, 0x16F4F, gb_extend},
+  {0x16F51, 0x16F87, gb_spacingmark}, {0x16F8F, 0x16F92, gb_extend}, {0x16FE4, 0x16FE4, gb_extend},
+  {0x16FF0, 0x16FF1, gb_spacingmark}, {0x1BC9D, 0x1BC9E, gb_extend},
+  {0x1BCA0, 0x1BCA3, gb_control}, {0x1CF00, 0x1CF2D, gb_extend}, {0x1CF30, 0x1CF46, gb_extend},
+  {0x1D165, 0x1D165, gb_extend}, {0x1D166, 0x1D166, gb_spacingmark}, {0x1D167, 0x1D169, gb_extend},
+  {0x1D16D, 0x1D16D, gb_spacingmark}, {0x1D16E, 0x1D172, gb_extend},
+  {0x1D173, 0x1D17A, gb_control}, {0x1D17B, 0x1D182, gb_extend}, {0x1D185, 0x1D18B, gb_extend},
+  {0x1D1AA, 0x1D1AD, gb_extend}, {0x1D242, 0x1D244, gb_extend}, {0x1DA00, 0x1DA36, gb_extend},
+  {0x1DA3B, 0x1DA6C, gb_extend}, {0x1DA75, 0x1DA75, gb_extend}, {0x1DA84, 0x1DA84, gb_extend},
+  {0x1DA9B, 0x1DA9F, gb_extend}, {0x1DAA1, 0x1DAAF, gb_extend}, {0x1E000, 0x1E006, gb_extend},
+  {0x1E008, 0x1E018, gb_extend}, {0x1E01B, 0x1E021, gb_extend}, {0x1E023, 0x1E024, gb_extend},
+  {0x1E026, 0x1E02A, gb_extend}, {0x1E08F, 0x1E08F, gb_extend}, {0x1E130, 0x1E136, gb_extend},
+  {0x1E2AE, 0x1E2AE, gb_extend}, {0x1E2EC, 0x1E2EF, gb_extend}, {0x1E4EC, 0x1E4EF, gb_extend},
+  {0x1E8D0, 0x1E8D6, gb_extend}, {0x1E944, 0x1E94A, gb_extend},
+  {0x1F000, 0x1F0FF, gb_pictographic}, {0x1F10D, 0x1F10F, gb_pictographic},
+  {0x1F12F, 0x1F12F, gb_pictographic}, {0x1F16C, 0x1F171, gb_pictographic},
+  {0x1F17E, 0x1F17F, gb_pictographic}, {0x1F18E, 0x1F18E, gb_pictographic},
+  {0x1F191, 0x1F19A, gb_pictographic}, {0x1F1AD, 0x1F1E5, gb_pictographic},
+  {0x1F1E6, 0x1F1FF, gb_regional}, {0x1F201, 0x1F20F, gb_pictographic},
+  {0x1F21A, 0x1F21A, gb_pictographic}, {0x1F22F, 0x1F22F, gb_pictographic},
+  {0x1F232, 0x1F23A, gb_pictographic}, {0x1F23C, 0x1F23F, gb_pictographic},
+  {0x1F249, 0x1F3FA, gb_pictographic}, {0x1F3FB, 0x1F3FF, gb_extend},
+  {0x1F400, 0x1F53D, gb_pictographic}, {0x1F546, 0x1F64F, gb_pictographic},
+  {0x1F680, 0x1F6FF, gb_pictographic}, {0x1F774, 0x1F77F, gb_pictographic},
+  {0x1F7D5, 0x1F7FF, gb_pictographic}, {0x1F80C, 0x1F80F, gb_pictographic},
+  {0x1F848, 0x1F84F, gb_pictographic}, {0x1F85A, 0x1F85F, gb_pictographic},
+  {0x1F888, 0x1F88F, gb_pictographic}, {0x1F8AE, 0x1F8FF, gb_pictographic},
+  {0x1F90C, 0x1F93A, gb_pictographic}, {0x1F93C, 0x1F945, gb_pictographic},
+  {0x1F947, 0x1FAFF, gb_pictographic}, {0x1FC00, 0x1FFFD, gb_pictographic},
+  {0xE0000, 0xE001F, gb_control}, {0xE0020, 0xE007F, gb_extend}, {0xE0080, 0xE00FF, gb_control},
+  {0xE0100, 0xE01EF, gb_extend}, {0xE01F0, 0xE0FFF, gb_control},
+}
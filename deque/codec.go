@@ -0,0 +1,67 @@
+/* Copyright (C) 2012 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named codec.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package deque
+
+import (
+          "bytes"
+          "encoding/gob"
+          "encoding/json"
+       )
+
+// Converts items to bytes and back for storing them outside of memory.
+type Codec[T any] interface {
+  Encode(item T) ([]byte, error)
+  Decode(data []byte) (T, error)
+}
+
+// A Codec that uses encoding/gob. Every item is encoded as a self-contained
+// gob stream, so items can be decoded independently of each other.
+//
+// NOTE: If T is an interface type such as interface{}, the concrete types
+// of the items need to be registered with gob.Register().
+type GobCodec[T any] struct{}
+
+func (GobCodec[T]) Encode(item T) ([]byte, error) {
+  var buf bytes.Buffer
+  // Passing a pointer makes gob encode interface values as interfaces,
+  // so that they can be decoded into T again.
+  err := gob.NewEncoder(&buf).Encode(&item)
+  return buf.Bytes(), err
+}
+
+func (GobCodec[T]) Decode(data []byte) (item T, err error) {
+  err = gob.NewDecoder(bytes.NewReader(data)).Decode(&item)
+  return item, err
+}
+
+// A Codec that uses encoding/json.
+//
+// NOTE: If T is interface{}, items are decoded as the generic JSON types
+// (e.g. numbers as float64, objects as map[string]interface{}).
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encode(item T) ([]byte, error) { return json.Marshal(item) }
+
+func (JSONCodec[T]) Decode(data []byte) (item T, err error) {
+  err = json.Unmarshal(data, &item)
+  return item, err
+}
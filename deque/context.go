@@ -0,0 +1,193 @@
+/* Copyright (C) 2012 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named context.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package deque
+
+import (
+          "context"
+       )
+
+/*********************************************************************************
+
+                   CONTEXT-AWARE BLOCKING
+
+*********************************************************************************/
+
+// The following functions are variants of the blocking functions that wait
+// until the respective condition is met or ctx is done, whichever
+// happens first. If ctx is done, they return ctx.Err() and the Deque
+// remains unchanged. If ctx is already done when the function is called,
+// it returns ctx.Err() right away, even if it could complete without
+// blocking.
+
+// Like Push() but gives up if ctx is done while waiting for space.
+// Returns false and nil if the item was discarded due to Growth().
+func (self *Deque) PushCtx(ctx context.Context, item interface{}) (bool, error) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if err := ctx.Err(); err != nil { return false, err }
+  for {
+    res := self.insertAt(self.count, item)
+    if res != 0 { return res > 0, nil }
+    if err := self.waitForCtx(&self.hasSpace, ctx); err != nil { return false, err }
+  }
+}
+
+// Like Next() but gives up if ctx is done while waiting for an item.
+func (self *Deque) NextCtx(ctx context.Context) (interface{}, error) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if err := self.waitForItemCtx(ctx); err != nil { return nil, err }
+  return self.removeAt(0), nil
+}
+
+// Like Pop() but gives up if ctx is done while waiting for an item.
+func (self *Deque) PopCtx(ctx context.Context) (interface{}, error) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if err := self.waitForItemCtx(ctx); err != nil { return nil, err }
+  return self.removeAt(self.count-1), nil
+}
+
+// Like WaitForItem() but waits until ctx is done instead of a timeout.
+// Returns nil if there is an item.
+func (self *Deque) WaitForItemCtx(ctx context.Context) error {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.waitForItemCtx(ctx)
+}
+
+// Like WaitForSpace() but waits until ctx is done instead of a timeout.
+// Returns nil if there is a free slot.
+func (self *Deque) WaitForSpaceCtx(ctx context.Context) error {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if err := ctx.Err(); err != nil { return err }
+  for ; self.count == len(self.data) ; {
+    if err := self.waitForCtx(&self.hasSpace, ctx); err != nil { return err }
+  }
+  return nil
+}
+
+// Like WaitForEmpty() but waits until ctx is done instead of a timeout.
+// Returns nil if the Deque is empty.
+func (self *Deque) WaitForEmptyCtx(ctx context.Context) error {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if err := ctx.Err(); err != nil { return err }
+  for ; self.count != 0 ; {
+    if err := self.waitForCtx(&self.isEmpty, ctx); err != nil { return err }
+  }
+  return nil
+}
+
+// The caller is responsible for locking self.
+func (self *Deque) waitForItemCtx(ctx context.Context) error {
+  if self.data == nil { self.init() }
+  if err := ctx.Err(); err != nil { return err }
+  for ; self.count == 0 ; {
+    if err := self.waitForCtx(&self.hasItem, ctx); err != nil { return err }
+  }
+  return nil
+}
+
+func (self *Deque) waitForCtx(what *[]chan bool, ctx context.Context) error {
+  return self.ring.waitForCtx(&self.Mutex, what, ctx)
+}
+
+
+// See Deque.PushCtx().
+func (self *Of[T]) PushCtx(ctx context.Context, item T) (bool, error) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if err := ctx.Err(); err != nil { return false, err }
+  for {
+    res := self.insertAt(self.count, item, self.Growth, &self.GrowthCount)
+    if res != 0 { return res > 0, nil }
+    if err := self.waitForCtx(&self.hasSpace, ctx); err != nil { return false, err }
+  }
+}
+
+// See Deque.NextCtx().
+func (self *Of[T]) NextCtx(ctx context.Context) (item T, err error) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if err = self.waitForItemCtx(ctx); err != nil { return item, err }
+  item, _ = self.removeAt(0)
+  return item, nil
+}
+
+// See Deque.PopCtx().
+func (self *Of[T]) PopCtx(ctx context.Context) (item T, err error) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if err = self.waitForItemCtx(ctx); err != nil { return item, err }
+  item, _ = self.removeAt(self.count-1)
+  return item, nil
+}
+
+// See Deque.WaitForItemCtx().
+func (self *Of[T]) WaitForItemCtx(ctx context.Context) error {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.waitForItemCtx(ctx)
+}
+
+// See Deque.WaitForSpaceCtx().
+func (self *Of[T]) WaitForSpaceCtx(ctx context.Context) error {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if err := ctx.Err(); err != nil { return err }
+  for ; self.count == len(self.data) ; {
+    if err := self.waitForCtx(&self.hasSpace, ctx); err != nil { return err }
+  }
+  return nil
+}
+
+// See Deque.WaitForEmptyCtx().
+func (self *Of[T]) WaitForEmptyCtx(ctx context.Context) error {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if err := ctx.Err(); err != nil { return err }
+  for ; self.count != 0 ; {
+    if err := self.waitForCtx(&self.isEmpty, ctx); err != nil { return err }
+  }
+  return nil
+}
+
+// The caller is responsible for locking self.
+func (self *Of[T]) waitForItemCtx(ctx context.Context) error {
+  if self.data == nil { self.init() }
+  if err := ctx.Err(); err != nil { return err }
+  for ; self.count == 0 ; {
+    if err := self.waitForCtx(&self.hasItem, ctx); err != nil { return err }
+  }
+  return nil
+}
+
+func (self *Of[T]) waitForCtx(what *[]chan bool, ctx context.Context) error {
+  return self.ring.waitForCtx(&self.Mutex, what, ctx)
+}
@@ -105,6 +105,12 @@
 //     // get the most recent item (it remains in mru)
 //   item := mru.Peek(0)
 //
+// Example 6: Type-safe Deque without type assertions
+//
+//   intvec := deque.NewOf[int]([]int{3,1,10,4,0})
+//   intvec.Sort(func(a,b int)int{return a-b})
+//   smallest := intvec.Next()   // an int, not an interface{}
+//
 // Notes about design decisions:
 //  At() is not called Get()
 //       because Get() could be a function that removes the element it returns,
@@ -154,6 +160,7 @@ package deque // import "winterdrache.de/golib/deque"
 
 import (
           "fmt"
+          "iter"
           "sync"
           "time"
        )
@@ -178,29 +185,8 @@ type Deque struct {
   // mutex before changing GrowthFunc or GrowthCount unless you can otherwise
   // guarantee that no goroutine will access the Deque concurrently.
   Mutex sync.Mutex
-  // The following 3 slices are used for waiting for the respective conditions.
-  // A waiter will create a buffered channel and append it to the respective list,
-  // then wait for a signal on that channel.
-  hasItem []chan bool
-  isEmpty []chan bool
-  hasSpace []chan bool
-  data []interface{}
-  // Current item count. Not to be confused with capacity (which is len(data)).
-  count int
-  // Index of the element At(0). The next Insert() call will write to
-  // (a-1+capacity) % capacity .
-  // a==b occurs if the Deque is either empty or full. count distinguishes these cases.
-  a int
-  // Index that the next Push() will write to (if the Deque is not full).
-  // Peek(0) returns the item at index (b-1+capacity) % capacity.
-  //  If a<b, then the slice data[a:b] contains all items in order.
-  //  If a>b, then data[a:] is the 1st and data[:b] the 2nd part.
-  //  If a==b and count == len(data), then data[a:] is the 1st and data[:b] the 2nd part.
-  //  If a==b and count == 0, then data[a:b] (an empty slice) contains all items.
-  // Combined that is:
-  //  If a<b || count == 0, all items are found in data[a:b]
-  //  Otherwise all items are found in data[a:] followed by data[:b]
-  b int
+  // The ring buffer holding the items, and the lists of waiters.
+  ring[interface{}]
 }
 
 
@@ -482,25 +468,7 @@ func (self *Deque) Overcapacity(remaining uint) *Deque {
   self.Mutex.Lock()
   defer self.Mutex.Unlock()
   if self.data == nil { self.init() }
-  r := len(self.data) - self.count
-  if uint(r) != remaining {
-    new_buf := make([]interface{},self.count + int(remaining))
-    if self.a < self.b {
-      copy(new_buf[0:], self.data[self.a:self.b])
-    } else { // this includes the cases where a==b and self.count==0
-      copy(new_buf[copy(new_buf[0:], self.data[self.a:]):], self.data[0:self.b])
-    }
-    
-    self.data = new_buf
-    self.a = 0
-    if self.count == len(self.data) {
-      self.b = 0
-    } else {
-      self.b = self.count
-      for _,c := range self.hasSpace { c <- true } 
-      self.hasSpace = self.hasSpace[0:0]
-    }
-  }
+  self.ring.overcapacity(remaining)
   return self
 }
 
@@ -777,6 +745,63 @@ func (self *Deque) Put(idx int, item interface{}) interface{} {
 
 
 
+/*********************************************************************************
+
+                   BATCH METHODS
+
+*********************************************************************************/
+
+// Pushes all items in order as if Push() was called for each of them, but
+// locks the Deque only once. If the items don't fit, Growth() is called
+// with the number of missing slots as additional capacity, so that the
+// overflow policy applies to the batch as a whole. E.g. with
+// DropFarEndIfOverflow the oldest items are dropped to make room (if the batch
+// is larger than the capacity, only its last items remain), with
+// DropItemIfOverflow the items that don't fit are discarded and with
+// BlockIfFull (or if Growth() grows less than requested) the items that fit
+// are added and PushMany() blocks until there is space for the rest.
+// Returns the number of items that were not discarded.
+func (self *Deque) PushMany(items... interface{}) int {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  added := 0
+  for {
+    c, a := self.pushMany(items, self.Growth, &self.GrowthCount)
+    added += a
+    items = items[c:]
+    if len(items) == 0 { return added }
+    self.waitFor(&self.hasSpace, 0)
+  }
+}
+
+// Blocks until either timeout has elapsed (0 means wait as long as necessary)
+// or at least one item is in the Deque, then removes up to n items
+// and returns them in the order in which Next() would have returned them.
+// Returns nil on timeout or if n <= 0.
+//
+// This is more efficient than calling Next() n times, because the Deque
+// is locked only once and waiters are woken at most once.
+func (self *Deque) NextN(n int, timeout time.Duration) []interface{} {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if n <= 0 || !self.waitForItemUntil(timeout) { return nil }
+  if n > self.count { n = self.count }
+  return self.removeFront(n, make([]interface{}, 0, n))
+}
+
+// Removes all items from the Deque and appends them to buf in the order in which
+// Next() would have returned them. Returns the extended buf, like append().
+// Does not block. Unlike Clear() this does not change the capacity.
+func (self *Deque) DrainTo(buf []interface{}) []interface{} {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  return self.removeFront(self.count, buf)
+}
+
+
 /*********************************************************************************
 
                    STRUCTURAL METHODS
@@ -804,14 +829,20 @@ func (self *Deque) Cat(lst... interface{}) *Deque {
 // Swaps the items At(i) and At(j). Returns nil if either index is
 // out of range, otherwise the Deque is returned.
 func (self *Deque) Swap(i, j int) *Deque { 
-  panic("TODO: Implement Swap()")
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if !self.swap(i, j) { return nil }
   return self
 }
 
 // Reverses the order of all elements, i.e. swapping At(i)<->Peek(i) for all i.
 // Returns the Deque.
 func (self *Deque) Reverse() *Deque { 
-  panic("TODO: Implement Reverse()")
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  self.reverse()
   return self
 }
 
@@ -821,7 +852,10 @@ func (self *Deque) Reverse() *Deque {
 // a positive value if it is greater and 0 if the arguments are equal.
 // Returns the Deque.
 func (self *Deque) Sort(cmp func(interface{},interface{}) int) *Deque { 
-  panic("TODO: Implement Sort()")
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  self.sort(cmp)
   return self 
 }
 
@@ -834,25 +868,11 @@ func (self *Deque) Remove(item interface{}, cmp... func(interface{},interface{})
   defer self.Mutex.Unlock()
   if self.data == nil { self.init() }
   
-  count := 0
-  
   switch len(cmp) {
-    case 0:
-      for i:=self.count-1; i >= 0 ; i-- {
-        idx := self.a + i
-        if idx >= len(self.data) { idx -= len(self.data) }
-        if self.data[idx] == item { self.removeAt(i); count++ } 
-      }
-    case 1:
-      for i:=self.count-1; i >= 0 ; i-- {
-        idx := self.a + i
-        if idx >= len(self.data) { idx -= len(self.data) }
-        if cmp[0](self.data[idx], item) == 0 { self.removeAt(i); count++ } 
-      }
+    case 0: return self.remove(func(x interface{}) bool { return x == item })
+    case 1: return self.remove(func(x interface{}) bool { return cmp[0](x, item) == 0 })
     default: panic("Remove() takes 1 or 2 parameters")
   }
-
-  return count
 }
 
 // Returns the index of the first element that compares equal
@@ -860,8 +880,14 @@ func (self *Deque) Remove(item interface{}, cmp... func(interface{},interface{})
 // a comparison function (see Sort()) passed as optional second argument.
 // Returns -1 if no such element exists.
 func (self *Deque) IndexOf(item interface{}, cmp... func(interface{},interface{}) int) int {
-  panic("TODO: Implement IndexOf()")
-  return -1 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  switch len(cmp) {
+    case 0: return self.indexOf(func(x interface{}) bool { return x == item })
+    case 1: return self.indexOf(func(x interface{}) bool { return cmp[0](x, item) == 0 })
+    default: panic("IndexOf() takes 1 or 2 parameters")
+  }
 }
 
 // When called on a Deque that has been sorted by Sort() with the same cmp() function
@@ -913,24 +939,8 @@ func (self *Deque) Contains(item interface{}, cmp... func(interface{},interface{
 func (self *Deque) CheckInvariant() {
   self.Mutex.Lock()
   defer self.Mutex.Unlock()
-  if self.count < 0 || self.count > len(self.data) { panic("invariant broken") }
-  if self.a < 0 || self.b < 0 { panic("invariant broken") }
-  if len(self.data) != 0 && (self.a >= len(self.data) || self.b >= len(self.data)) { panic("invariant broken") }
-  if len(self.data) == 0 && (self.a != 0 || self.b != 0) { panic("invariant broken") }
-  if self.a == self.b && self.count != 0 && self.count != len(self.data) { panic("invariant broken") }
-  if (self.count == 0 || self.count == len(self.data)) && self.a != self.b { panic("invariant broken") }
-  if self.a < self.b && self.count != self.b-self.a { panic("invariant broken") }
-  if self.b < self.a && self.count != (self.b + len(self.data)-self.a) { panic("invariant broken") }
+  self.ring.checkInvariant()
   if self.Growth == nil && self.data != nil { panic("invariant broken") }
-  for _, ar := range []*[]chan bool{&self.hasItem,&self.isEmpty,&self.hasSpace} {
-    for _, c := range *ar {
-      if len(c) != 0 || cap(c) != 2 { panic("invariant broken") }
-    }
-  }
-  if len(self.data) != cap(self.data) { panic("invariant broken") }
-  if self.count == 0 && len(self.isEmpty) != 0 { panic("invariant broken") }
-  if self.count != 0 && len(self.hasItem) != 0 { panic("invariant broken") }
-  if self.count < len(self.data) && len(self.hasSpace) != 0 { panic("invariant broken") }
 }
 
 /*********************************************************************************
@@ -945,10 +955,8 @@ func (self *Deque) String() string {
   defer self.Mutex.Unlock()
   if self.data == nil { self.init() }
   buf := make([]string, self.count)
-  for i:=0; i < self.count; i++ {
-    j := self.a+i
-    if j >= len(self.data) { j -= len(self.data) }
-    buf[i] = fmt.Sprintf("%v", self.data[j])
+  for i := range buf {
+    buf[i] = fmt.Sprintf("%v", self.data[self.index(i)])
   }
   return fmt.Sprintf("Deque%v",buf)
 }
@@ -968,29 +976,7 @@ func (self *Deque) String() string {
 // of the returned slice (e.g. sorting them), but adding or removing elements 
 // will not work, because you cannot modify the element count.
 func (self *Deque) Raw(index0 int) (ring []interface{}, idx0 int) { 
-  if len(self.data) == 0 { return self.data, 0 } // Avoid division by 0
-  if index0 >= 0 {
-    index0 = index0 % len(self.data)
-    if index0 != self.a {
-      new_buf := make([]interface{}, len(self.data))
-      if index0 > self.a {
-        // |-------A---0-----|
-        copy(new_buf[index0:], self.data[self.a:])
-        copy(new_buf[0:], self.data[self.a+len(self.data)-index0:])
-        copy(new_buf[index0-self.a:], self.data[0:self.a])
-      } else {
-        // |-------0---A-----|
-        copy(new_buf[index0:], self.data[self.a:])
-        copy(new_buf[index0+len(self.data)-self.a:], self.data[0:])
-        copy(new_buf[0:], self.data[self.a-index0:self.a])
-      }
-      self.data = new_buf
-      self.a = index0
-      self.b = self.a + self.count
-      if self.b >= len(self.data) { self.b -= len(self.data) }
-    }
-  }
-  return self.data, self.a
+  return self.raw(index0)
 }
 
 
@@ -1001,12 +987,25 @@ func (self *Deque) Raw(index0 int) (ring []interface{}, idx0 int) {
 
 *********************************************************************************/
 
-func (self* Deque) Iterator() Iterator {
-  panic("TODO: Implement Iterator()")
-  return nil
+// Returns an Iterator over the Deque. Without flags the Iterator goes from
+// At(0) to Peek(0) over a snapshot of the Deque's items that is copied
+// while the Deque is locked, so it is unaffected by later changes to the Deque.
+// See the constants REVERSE and LIVE for the supported flags.
+func (self *Deque) Iterator(flags... int) Iterator {
+  return newIterator(&self.Mutex, &self.ring, flags)
+}
+
+// Returns a snapshot iterator for range-over-func loops that yields all
+// items from At(0) to Peek(0):
+//   for item := range d.All() { ... }
+// Because it iterates over a snapshot, the loop body may modify the Deque.
+func (self *Deque) All() iter.Seq[interface{}] {
+  return self.Iterator().Values()
 }
 
-type Iterator interface{
+// Like All() but yields the items from Peek(0) to At(0).
+func (self *Deque) Backward() iter.Seq[interface{}] {
+  return self.Iterator(REVERSE).Values()
 }
 
 
@@ -1070,292 +1069,68 @@ func (self *Deque) init(args... interface{}) *Deque {
   for _, x := range args {
     switch arg := x.(type) {
       case *Deque: 
-             if arg.data != nil { // protect against uninitialized Deques
-               if arg.a < arg.b || arg.count == 0 {
-                 new_data = append(new_data, arg.data[arg.a:arg.b]...)
-               } else {
-                 new_data = append(new_data, arg.data[arg.a:]...)
-                 new_data = append(new_data, arg.data[:arg.b]...)
-               }
-             }
+             new_data = arg.appendTo(new_data)
       case []interface{}: new_data = append(new_data, arg...)
     }
   }
   
-  self.count = len(new_data)
-  self.a = 0
-  self.b = self.count
-  
-  // grow new_data slice to full capacity
-  new_data = new_data[0:cap(new_data)]
-  // wrap around b if beyond end
-  if self.b == len(new_data) { self.b = 0 }
-  
-  self.data = new_data
+  self.reset(new_data)
   
   if self.Growth == nil && new_growth == nil { new_growth = GrowthDefault }
   if new_growth != nil { self.Growth = new_growth }
   
   self.GrowthCount = 0
   
-  // notify waiters based on new state
-  if self.count > 0 { 
-    for _,c := range self.hasItem { c <-true } 
-    self.hasItem = self.hasItem[0:0]
-  }
-  if self.count < len(self.data) { 
-    for _,c := range self.hasSpace { c <- true } 
-    self.hasSpace = self.hasSpace[0:0]
-  }
-  if self.count == 0 { 
-    for _,c := range self.isEmpty { c <- true } 
-    self.isEmpty = self.isEmpty[0:0]
-  }
-
   return self
 }
 
 
 func (self *Deque) waitFor(what *[]chan bool, timeout time.Duration) bool {
-  c := make(chan bool, 2)
-  *what = append(*what, c)
-  self.Mutex.Unlock()
-  defer self.Mutex.Lock()
-  if timeout > 0 {
-    go func(){
-      time.Sleep(timeout)
-      c <- false
-    }()
-  }
-  return <-c // wait for signal or timeout
+  return self.ring.waitFor(&self.Mutex, what, timeout)
 }
 
-
+// Waits for an item as long as timeout permits. Other than a single waitFor()
+// this copes with items being taken by other goroutines before this one
+// gets the lock. Returns false on timeout.
+func (self *Deque) waitForItemUntil(timeout time.Duration) bool {
+  deadline := time.Now().Add(timeout)
+  for ; self.count == 0 ; {
+    remaining := time.Duration(0)
+    if timeout > 0 {
+      remaining = time.Until(deadline)
+      if remaining <= 0 { return false }
+    }
+    self.waitFor(&self.hasItem, remaining)
+  }
+  return true
+}
 
 func (self *Deque) at(idx int) interface{} { 
   if self.data == nil { self.init() }
-  if idx < 0 || idx >= self.count { return nil }
-  idx += self.a
-  if idx >= len(self.data) { idx -= len(self.data) }
-  return self.data[idx]
+  item, _ := self.ring.at(idx)
+  return item
 }
 
 func (self *Deque) put(idx int, item interface{}) interface{} { 
   if self.data == nil { self.init() }
-  if idx < 0 || idx >= self.count { return nil }
-  idx += self.a
-  if idx >= len(self.data) { idx -= len(self.data) }
-  old := self.data[idx]
-  self.data[idx] = item
+  old, _ := self.ring.put(idx, item)
   return old
 }
 
 func (self *Deque) search(item interface{}, cmp func(interface{},interface{}) int) int {
-  a := 0
-  b := self.count
-  for a != b {
-    h := (a+b) >> 1
-    if cmp(self.at(h), item) < 0 {
-      a = h+1
-    } else {
-      b = h
-    }
-  }
-  return a
+  if self.data == nil { self.init() }
+  return self.ring.search(item, cmp)
 }
 
-//*************************** removeAt() ******************************/
+// See ring.removeAt(). Returns nil if idx is out of range.
 func (self *Deque) removeAt(idx int) interface{} {
   if self.data == nil { self.init() }
-  if idx < 0 || idx >= self.count { return nil }
-  idx += self.a
-  if idx >= len(self.data) { idx -= len(self.data) }
-  
-  old := self.data[idx]
-  
-  if self.a < self.b { //simple case: |...A----idx----B...|
-    if idx-self.a < self.b-idx { // idx closer to A than to B
-      copy(self.data[self.a+1:],self.data[self.a:idx])
-      self.a++
-    } else { // idx closer to B than to A
-      copy(self.data[idx:],self.data[idx+1:self.b])
-      self.b--
-    }
-  } else { //harder case:   |--idx?--B.........A---idx?--|
-    da := idx - self.a
-    if da < 0 { da += len(self.data) }
-    db := self.b - idx
-    if db < 0 { db += len(self.data) }
-    if da <= db { // |--idx?----------------B.........A--idx?-|
-                  // including cases where B == A
-                  // including the special case B == A == idx
-      if idx < self.b { 
-        copy(self.data[1:], self.data[0:idx])
-        idx = len(self.data)-1
-        self.data[0] = self.data[idx]
-      }
-      copy(self.data[self.a+1:], self.data[self.a:idx])
-      self.a++
-      if self.a == len(self.data) { self.a = 0 }
-    } else { // |--idx?--B.........A-----------------idx?--|
-             // including cases where B == A
-      if idx >= self.a {
-        copy(self.data[idx:],self.data[idx+1:])
-        self.data[len(self.data)-1] = self.data[0]
-        idx = 0
-      }
-      // self.b+1 is used instead of self.b, because
-      // it is possible that self.b==0 and in that case
-      // idx+1 > self.b and the Go specs say about slices that
-      // low <= high must be satisfied.
-      // The +1 causes one additional slot to be copied but
-      // that doesn't matter because the slot it overwrites (self.b-1)
-      // is definitely unused once we do self.b--.
-      copy(self.data[idx:],self.data[idx+1:self.b+1])
-      self.b--
-      if self.b < 0 { self.b += len(self.data) }
-    }
-  }
-  
-  if self.count == len(self.data) { // we went from full to 1 available slot
-    for _,c := range self.hasSpace { c <- true } 
-    self.hasSpace = self.hasSpace[0:0]
-  }
-  self.count--
-
-  if self.count == 0 { // deque is now empty
-    for _,c := range self.isEmpty { c <- true } 
-    self.isEmpty = self.isEmpty[0:0]
-  }
-
+  old, _ := self.ring.removeAt(idx)
   return old
 }
 
-
-//*************************** insertAt() ******************************/
-// Returns <0  => item discarded or index out of range
-//         >0 => item added successfully
-//          0 => wait for free space, then try again
+// See ring.insertAt().
 func (self *Deque) insertAt(idx int, item interface{}) int {
   if self.data == nil { self.init() }
-  if idx < 0 || idx > self.count { // Note: self.count IS a valid idx for insertAt()!
-    return -1
-  }
-  
-  // If buffer is full, grow it or wait for an empty slot.
-  if self.count == len(self.data) {
-    growth := self.Growth(uint(len(self.data)), 1, self.GrowthCount)
-    self.GrowthCount++
-    switch growth {
-      case 0: { // no growth => block until there's space
-        return 0
-      }
-      
-      case DROP_FAR_END: { // drop far end
-        if len(self.data) == 0 { return -1 }
-        if idx+idx > self.count { // A end is far end
-          self.a++
-          if self.a == len(self.data) { self.a = 0 }
-          idx--
-        } else { // B end is far end
-          self.b--
-          if self.b < 0 { self.b += len(self.data) }
-        }
-        self.count--
-      }
-      
-      case DISCARD: { // discard the new item
-        return -1
-      }        
-      
-      default: { // grow buffer
-        new_buf := make([]interface{}, len(self.data) + int(growth))
-        // Note: self.a == self.b because buffer is full
-        copy(new_buf, self.data[self.a:len(self.data)])
-        copy(new_buf[len(self.data)-self.a:], self.data[0:self.a])
-        self.a = 0
-        self.b = self.count
-        self.data = new_buf
-        
-        if growth > 1 { // if we grew more than necessary, signal waiters for space
-          for _,c := range self.hasSpace { c <- true } 
-          self.hasSpace = self.hasSpace[0:0]
-        }
-      }
-    }
-  }
-  
-  // At this point we have an empty slot. Let's insert!
-  
-  idx += self.a
-  if idx >= len(self.data) { idx -= len(self.data) }
-  
-  if self.a <= self.b { //simple case: |...A----idx----B...|
-                        //including the case A == B == idx (empty Deque)
-    if idx-self.a < self.b-idx { // idx closer to A than to B
-      a := self.a
-      self.a--
-      if self.a < 0 { self.a += len(self.data) }
-      // copy 1st element separately because of possible wrap-around
-      self.data[self.a] = self.data[a]
-      
-      // We use idx+1 because it is possible that idx==a and
-      // the Go specs say that low <= high must be satisfied.
-      // This may cause data[idx] to be copied to data[idx-1]
-      // but this unnecessary copy doesn't hurt.
-      copy(self.data[a:], self.data[a+1:idx+1])
-      idx-- // insert before the item
-      if idx < 0 { idx += len(self.data) }
-    } else { // idx closer to B than to A 
-             //   or 
-             // A == B == idx (empty Deque)
-      // because self.data[self.b] is an empty slot, the following
-      // copy cannot wrap around unlike the "closer to A" case
-      copy(self.data[idx+1:len(self.data)], self.data[idx:self.b])
-      self.b++
-      if self.b == len(self.data) { self.b = 0 }
-    }
-  } else { //harder case:   |--idx?--B.........A---idx?--|
-           //Note that A != B because the Deque cannot be full and 
-           //the case of an empty Deque is handled above.
-    da := idx - self.a
-    if da < 0 { da += len(self.data) }
-    db := self.b - idx
-    if db < 0 { db += len(self.data) }
-    if da < db { // |--idx?----------------B.........A--idx?-|
-      idx-- // insert before the item
-      if idx < 0 { idx += len(self.data) }
-      self.a-- // cannot wrap around
-      if idx >= self.a { // |----------------------B.........A--idx--|
-        copy(self.data[self.a:], self.data[self.a+1:idx+1])
-      } else 
-      { // |--idx-----------------B.........A-------|
-        copy(self.data[self.a:], self.data[self.a+1:len(self.data)])
-          // copy 1st element separately because of wrap-around
-        self.data[len(self.data)-1] = self.data[0]
-        copy(self.data[0:], self.data[1:idx+1])
-      } 
-    } else { // |--idx?--B.........A-----------------idx?--|
-      if idx <= self.b { // |--idx--B.........A-----------------------|
-        copy(self.data[idx+1:], self.data[idx:self.b])
-        self.b++ // cannot wrap around
-      } else
-      { // |------B.........A-----------------idx--|
-        copy(self.data[1:], self.data[0:self.b])
-        self.data[0] = self.data[len(self.data)-1]
-        copy(self.data[idx+1:len(self.data)], self.data[idx:len(self.data)-1])
-        self.b++ // cannot wrap around
-      }
-    }
-  }
-
-  self.data[idx] = item
-
-  if self.count == 0 { // we inserted into an empty deque => signal waiters for item
-    for _,c := range self.hasItem { c <- true } 
-    self.hasItem = self.hasItem[0:0]
-  }
-  self.count++
-  
-  return 1
+  return self.ring.insertAt(idx, item, self.Growth, &self.GrowthCount)
 }
@@ -0,0 +1,477 @@
+/* Copyright (C) 2012 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named generic.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package deque
+
+import (
+          "fmt"
+          "iter"
+          "sync"
+          "time"
+       )
+
+// Of[T] is the type-safe counterpart of Deque. It has the same semantics
+// (Growth functions, overflow policies, blocking and waiting) and shares the
+// ring buffer implementation with Deque, but stores items of type T directly
+// without boxing them into interface{} values, so that no type assertions
+// are needed.
+//
+// Like a Deque, an Of[T] is ready for use when declared. Where a Deque method
+// returns nil to signal an out of range index, the corresponding Of[T] method
+// returns the zero value of T.
+//
+//   var ints deque.Of[int]
+//   ints.Push(42)
+//   sum := ints.Pop() + 1
+//
+//   queue := deque.NewOf[string](32, deque.BlockIfFull)
+type Of[T any] struct {
+  // See the documentation for the type GrowthFunc.
+  Growth GrowthFunc
+  // Counts the number of times Growth() has been called.
+  GrowthCount uint
+  // See Deque.Mutex.
+  Mutex sync.Mutex
+  // The ring buffer holding the items, and the lists of waiters.
+  ring[T]
+}
+
+// Creates a new Of[T], calls Init(args) on it and returns a pointer to it.
+func NewOf[T any](args... interface{}) *Of[T] {
+  var d Of[T]
+  return d.Init(args...)
+}
+
+// Like Deque.Init(), except that the items arguments are of type []T and *Of[T].
+func (self *Of[T]) Init(args... interface{}) *Of[T] {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.init(args...)
+}
+
+// Calls Init() with no arguments.
+func (self *Of[T]) Clear() *Of[T] {
+  return self.Init()
+}
+
+// See Deque.Count().
+func (self *Of[T]) Count() int { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  return self.count
+}
+
+// See Deque.Capacity().
+func (self *Of[T]) Capacity() int { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  return len(self.data)
+}
+
+// See Deque.Overcapacity().
+func (self *Of[T]) Overcapacity(remaining uint) *Of[T] { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  self.ring.overcapacity(remaining)
+  return self
+}
+
+// See Deque.IsEmpty().
+func (self *Of[T]) IsEmpty() bool { return self.Count()==0 }
+
+// See Deque.IsFull().
+func (self *Of[T]) IsFull() bool { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  return len(self.data) == self.count
+}
+
+// See Deque.WaitForItem().
+func (self *Of[T]) WaitForItem(timeout time.Duration) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if self.count > 0 { return true }
+  return self.waitFor(&self.hasItem, timeout)
+}
+
+// See Deque.WaitForSpace().
+func (self *Of[T]) WaitForSpace(timeout time.Duration) bool { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if self.count < len(self.data) { return true }
+  return self.waitFor(&self.hasSpace, timeout)
+}
+
+// See Deque.WaitForEmpty().
+func (self *Of[T]) WaitForEmpty(timeout time.Duration) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if self.count == 0 { return true }
+  return self.waitFor(&self.isEmpty, timeout)
+}
+
+// See Deque.Push().
+func (self *Of[T]) Push(item T) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.insert(func() int { return self.count }, item)
+}
+
+// See Deque.PushAt().
+func (self *Of[T]) PushAt(idx int, item T) bool { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.insert(func() int { return self.count-idx }, item)
+}
+
+// See Deque.Pop().
+func (self *Of[T]) Pop() T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  for ; self.count == 0 ; {
+    self.waitFor(&self.hasItem, 0)
+  }
+  item, _ := self.removeAt(self.count-1)
+  return item
+}
+
+// See Deque.PopAt(). Returns the zero value if idx is out of range.
+func (self *Of[T]) PopAt(idx int) T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  item, _ := self.removeAt(self.count-1-idx)
+  return item
+}
+
+// See Deque.Peek(). Returns the zero value if idx is out of range.
+func (self *Of[T]) Peek(idx int) T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  item, _ := self.at(self.count-1-idx)
+  return item
+}
+
+// See Deque.Poke(). Returns the zero value if idx is out of range.
+func (self *Of[T]) Poke(idx int, item T) T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  old, _ := self.put(self.count-1-idx, item)
+  return old
+}
+
+// See Deque.Insert().
+func (self *Of[T]) Insert(item T) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.insert(func() int { return 0 }, item)
+}
+
+// See Deque.InsertAt().
+func (self *Of[T]) InsertAt(idx int, item T) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.insert(func() int { return idx }, item)
+}
+
+// See Deque.Next().
+func (self *Of[T]) Next() T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  for ; self.count == 0 ; {
+    self.waitFor(&self.hasItem, 0)
+  }
+  item, _ := self.removeAt(0)
+  return item
+}
+
+// See Deque.RemoveAt(). Returns the zero value if idx is out of range.
+func (self *Of[T]) RemoveAt(idx int) T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  item, _ := self.removeAt(idx)
+  return item
+}
+
+// See Deque.At(). Returns the zero value if idx is out of range.
+func (self *Of[T]) At(idx int) T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  item, _ := self.at(idx)
+  return item
+}
+
+// See Deque.Put(). Returns the zero value if idx is out of range.
+func (self *Of[T]) Put(idx int, item T) T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  old, _ := self.put(idx, item)
+  return old
+}
+
+// See Deque.PushMany().
+func (self *Of[T]) PushMany(items... T) int {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  added := 0
+  for {
+    c, a := self.pushMany(items, self.Growth, &self.GrowthCount)
+    added += a
+    items = items[c:]
+    if len(items) == 0 { return added }
+    self.waitFor(&self.hasSpace, 0)
+  }
+}
+
+// See Deque.NextN().
+func (self *Of[T]) NextN(n int, timeout time.Duration) []T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if n <= 0 || !self.waitForItemUntil(timeout) { return nil }
+  if n > self.count { n = self.count }
+  return self.removeFront(n, make([]T, 0, n))
+}
+
+// See Deque.DrainTo().
+func (self *Of[T]) DrainTo(buf []T) []T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  return self.removeFront(self.count, buf)
+}
+
+// See Deque.Swap().
+func (self *Of[T]) Swap(i, j int) *Of[T] { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  if !self.swap(i, j) { return nil }
+  return self
+}
+
+// See Deque.Reverse().
+func (self *Of[T]) Reverse() *Of[T] { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  self.reverse()
+  return self
+}
+
+// See Deque.Sort().
+func (self *Of[T]) Sort(cmp func(T,T) int) *Of[T] { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  self.sort(cmp)
+  return self 
+}
+
+// See Deque.Remove(). Without a cmp function, items are compared with ==
+// after conversion to interface{}, so this panics if T is not comparable.
+func (self *Of[T]) Remove(item T, cmp... func(T,T) int) int {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  return self.remove(self.matcher("Remove", item, cmp))
+}
+
+// See Deque.IndexOf() and Remove().
+func (self *Of[T]) IndexOf(item T, cmp... func(T,T) int) int {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  return self.indexOf(self.matcher("IndexOf", item, cmp))
+}
+
+// See Deque.Search().
+func (self *Of[T]) Search(item T, cmp func(T,T) int) int { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  return self.search(item, cmp)
+}
+
+// See Deque.InsertSorted().
+func (self *Of[T]) InsertSorted(item T, cmp func(T,T) int) int { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  idx := -1
+  // idx must be recomputed on each restart!
+  if !self.insert(func() int { idx = self.search(item, cmp); return idx }, item) { return -1 }
+  return idx
+}
+
+// See Deque.Contains() and Remove().
+func (self *Of[T]) Contains(item T, cmp... func(T,T) int) bool { 
+  return self.IndexOf(item, cmp...)>=0 
+}
+
+// See Deque.CheckInvariant().
+func (self *Of[T]) CheckInvariant() {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  self.ring.checkInvariant()
+  if self.Growth == nil && self.data != nil { panic("invariant broken") }
+}
+
+// Returns a string representation of the Deque.
+func (self *Of[T]) String() string { 
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.data == nil { self.init() }
+  return fmt.Sprintf("Deque%v", self.appendTo(nil))
+}
+
+// See Deque.Raw().
+func (self *Of[T]) Raw(index0 int) (ring []T, idx0 int) { 
+  return self.raw(index0)
+}
+
+// See Deque.Iterator().
+func (self *Of[T]) Iterator(flags... int) *IteratorOf[T] {
+  return newIterator(&self.Mutex, &self.ring, flags)
+}
+
+// See Deque.All().
+func (self *Of[T]) All() iter.Seq[T] {
+  return self.Iterator().Values()
+}
+
+// See Deque.Backward().
+func (self *Of[T]) Backward() iter.Seq[T] {
+  return self.Iterator(REVERSE).Values()
+}
+
+// like Init() but the caller is responsible for locking self.
+func (self *Of[T]) init(args... interface{}) *Of[T] {
+  locklist := map[*Of[T]]bool{self:true}
+  
+  // evaluate arguments
+  requested_capacity := -1
+  cat_capacity := -1
+  var new_growth GrowthFunc
+  for i, x := range args {
+    switch arg := x.(type) {
+      case *Of[T]: 
+             if !locklist[arg] { 
+               locklist[arg] = true
+               arg.Mutex.Lock()
+               defer arg.Mutex.Unlock()
+             }
+             if arg.data != nil { // protect against uninitialized Deques
+               if cat_capacity < 0 { cat_capacity = 0 }
+               cat_capacity += arg.count
+             }
+      case []T: 
+             if cat_capacity < 0 { cat_capacity = 0 }
+             cat_capacity += len(arg)
+      case int:  requested_capacity = arg
+      case uint: requested_capacity = int(arg)
+      case int64: requested_capacity = int(arg)
+      case uint64: requested_capacity = int(arg)
+      
+      case GrowthFunc: new_growth = arg
+      case func(uint, uint, uint) uint: new_growth = arg
+      default: panic(fmt.Errorf("Argument #%d is unsupported by deque.Init()",i+1))
+    }
+  }
+  
+  // See Deque.init() regarding CapacityDefault.
+  if cat_capacity > requested_capacity { requested_capacity = cat_capacity }
+  if requested_capacity < 0 { requested_capacity = int(CapacityDefault) }
+  
+  new_data := make([]T, 0, requested_capacity)
+  
+  // concatenate all initial items into new_data
+  for _, x := range args {
+    switch arg := x.(type) {
+      case *Of[T]: new_data = arg.appendTo(new_data)
+      case []T: new_data = append(new_data, arg...)
+    }
+  }
+  
+  self.reset(new_data)
+  
+  if self.Growth == nil && new_growth == nil { new_growth = GrowthDefault }
+  if new_growth != nil { self.Growth = new_growth }
+  
+  self.GrowthCount = 0
+  
+  return self
+}
+
+func (self *Of[T]) waitFor(what *[]chan bool, timeout time.Duration) bool {
+  return self.ring.waitFor(&self.Mutex, what, timeout)
+}
+
+// See Deque.waitForItemUntil().
+func (self *Of[T]) waitForItemUntil(timeout time.Duration) bool {
+  deadline := time.Now().Add(timeout)
+  for ; self.count == 0 ; {
+    remaining := time.Duration(0)
+    if timeout > 0 {
+      remaining = time.Until(deadline)
+      if remaining <= 0 { return false }
+    }
+    self.waitFor(&self.hasItem, remaining)
+  }
+  return true
+}
+
+// Inserts item at index idx() (which is re-evaluated after each wait for
+// free space), blocking as long as necessary. Returns false iff the item
+// was discarded or idx() is out of range. The caller must hold the Mutex.
+func (self *Of[T]) insert(idx func() int, item T) bool {
+  if self.data == nil { self.init() }
+  for {
+    res := self.insertAt(idx(), item, self.Growth, &self.GrowthCount)
+    if res < 0 { return false }
+    if res > 0 { return true }
+    self.waitFor(&self.hasSpace, 0)
+  }
+}
+
+// Returns a function that tests its argument for equality with item
+// per cmp[0] or operator ==.
+func (self *Of[T]) matcher(caller string, item T, cmp []func(T,T) int) func(T) bool {
+  switch len(cmp) {
+    case 0: return func(x T) bool { return interface{}(x) == interface{}(item) }
+    case 1: return func(x T) bool { return cmp[0](x, item) == 0 }
+  }
+  panic(caller+"() takes 1 or 2 parameters")
+}
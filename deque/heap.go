@@ -0,0 +1,303 @@
+/* Copyright (C) 2012 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named heap.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package deque
+
+import (
+          "context"
+          "fmt"
+          "slices"
+          "sync"
+          "time"
+       )
+
+// A priority queue. Push() and Next() are O(log n), Peek() is O(1).
+// Next() always returns the smallest item according to the comparison
+// function passed to NewHeapOf(), which has the same meaning as the cmp
+// function passed to Deque.Sort(). Items that compare equal are returned
+// in the order in which they were pushed.
+//
+// Push() returns a *Handle that can be used to Update() (e.g. change
+// the priority of) or Remove() the item later, both in O(log n).
+//
+// Like a Deque, a Heap is goroutine-safe and Next() blocks until an item is
+// available. Unlike a Deque, a Heap has no capacity limit, so Push() never
+// blocks. A Heap must be created with NewHeap() or NewHeapOf(). Unlike a
+// zero-value Deque, a zero-value Heap is NOT ready to use. It has no
+// comparison function, so the second Push() panics.
+//
+// Example: A scheduler
+//
+//   jobs := deque.NewHeapOf(func(a,b *Job) int { return a.Prio - b.Prio })
+//   h := jobs.Push(job)
+//   ...
+//   job.Prio = 0     // make job urgent
+//   jobs.Update(h, job)
+//   ...
+//   next := jobs.Next()
+type HeapOf[T any] struct {
+  // The Mutex that protects this Heap against concurrent access.
+  Mutex sync.Mutex
+  waiters
+  cmp func(T,T) int
+  // The heap itself: items[i] <= items[2*i+1] and items[i] <= items[2*i+2]
+  items []T
+  // handles[i] is the handle for items[i].
+  handles []*Handle
+  // The seq of the next Push().
+  seq uint64
+}
+
+// The interface{} version of HeapOf[T] whose comparison function is
+// compatible with Deque.Sort() and Deque.InsertSorted().
+type Heap = HeapOf[interface{}]
+
+// Identifies an item in a Heap for Update(), Remove() and At().
+// A Handle becomes invalid when its item leaves the Heap.
+type Handle struct {
+  // The Heap that contains the item. nil if the item has left the Heap.
+  owner interface{}
+  // The index of the item in the Heap's items.
+  index int
+  // Push() order for breaking ties between equal items.
+  seq uint64
+}
+
+// Creates a new, empty Heap ordered by cmp.
+func NewHeap(cmp func(interface{},interface{}) int) *Heap {
+  return NewHeapOf(cmp)
+}
+
+// Creates a new, empty HeapOf[T] ordered by cmp.
+func NewHeapOf[T any](cmp func(T,T) int) *HeapOf[T] {
+  return &HeapOf[T]{cmp: cmp}
+}
+
+// Returns the number of items in the Heap.
+func (self *HeapOf[T]) Count() int {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return len(self.items)
+}
+
+// Returns true iff no items are in the Heap. See Deque.IsEmpty().
+func (self *HeapOf[T]) IsEmpty() bool { return self.Count() == 0 }
+
+// Adds item to the Heap and returns its Handle.
+func (self *HeapOf[T]) Push(item T) *Handle {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  h := &Handle{owner: self, index: len(self.items), seq: self.seq}
+  self.seq++
+  self.items = append(self.items, item)
+  self.handles = append(self.handles, h)
+  self.up(h.index)
+  if len(self.items) == 1 { self.signal(&self.hasItem) }
+  return h
+}
+
+// Blocks until there is at least 1 item in the Heap, then removes and returns
+// the smallest item.
+func (self *HeapOf[T]) Next() T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  for ; len(self.items) == 0 ; {
+    self.waitFor(&self.Mutex, &self.hasItem, 0)
+  }
+  return self.removeAt(0)
+}
+
+// Like Next() but gives up if ctx is done while waiting for an item.
+// See Deque.NextCtx().
+func (self *HeapOf[T]) NextCtx(ctx context.Context) (item T, err error) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if err = ctx.Err(); err != nil { return item, err }
+  for ; len(self.items) == 0 ; {
+    if err = self.waitForCtx(&self.Mutex, &self.hasItem, ctx); err != nil { return item, err }
+  }
+  return self.removeAt(0), nil
+}
+
+// Returns the smallest item without removing it, or the zero value
+// if the Heap is empty.
+func (self *HeapOf[T]) Peek() (item T) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if len(self.items) == 0 { return item }
+  return self.items[0]
+}
+
+// Returns the item identified by h and true, or the zero value and false
+// if h's item is no longer in the Heap.
+func (self *HeapOf[T]) At(h *Handle) (item T, ok bool) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if h.owner != self { return item, false }
+  return self.items[h.index], true
+}
+
+// Replaces the item identified by h with item and moves it to its new
+// position. Use this to change an item's priority. The item keeps its
+// position relative to equal items. Returns false if h's item is no longer
+// in the Heap.
+func (self *HeapOf[T]) Update(h *Handle, item T) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if h.owner != self { return false }
+  self.items[h.index] = item
+  if !self.down(h.index) { self.up(h.index) }
+  return true
+}
+
+// Removes the item identified by h and returns it and true, or the zero value
+// and false if h's item is no longer in the Heap.
+func (self *HeapOf[T]) Remove(h *Handle) (item T, ok bool) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if h.owner != self { return item, false }
+  return self.removeAt(h.index), true
+}
+
+// Removes all items from the Heap. Returns the Heap.
+func (self *HeapOf[T]) Clear() *HeapOf[T] {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  for _, h := range self.handles { h.owner = nil }
+  self.items = nil
+  self.handles = nil
+  self.signal(&self.isEmpty)
+  return self
+}
+
+// See Deque.WaitForItem().
+func (self *HeapOf[T]) WaitForItem(timeout time.Duration) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if len(self.items) > 0 { return true }
+  return self.waitFor(&self.Mutex, &self.hasItem, timeout)
+}
+
+// See Deque.WaitForItemCtx().
+func (self *HeapOf[T]) WaitForItemCtx(ctx context.Context) error {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if err := ctx.Err(); err != nil { return err }
+  for ; len(self.items) == 0 ; {
+    if err := self.waitForCtx(&self.Mutex, &self.hasItem, ctx); err != nil { return err }
+  }
+  return nil
+}
+
+// See Deque.WaitForEmpty().
+func (self *HeapOf[T]) WaitForEmpty(timeout time.Duration) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if len(self.items) == 0 { return true }
+  return self.waitFor(&self.Mutex, &self.isEmpty, timeout)
+}
+
+// Returns a string representation of the Heap with the items in the
+// order in which Next() would return them.
+func (self *HeapOf[T]) String() string {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  order := make([]int, len(self.items))
+  for i := range order { order[i] = i }
+  slices.SortFunc(order, func(i, j int) int {
+    if self.less(i, j) { return -1 }
+    return 1
+  })
+  buf := make([]string, len(order))
+  for i, idx := range order { buf[i] = fmt.Sprintf("%v", self.items[idx]) }
+  return fmt.Sprintf("Heap%v", buf)
+}
+
+// Panics if the Heap is broken. See Deque.CheckInvariant().
+func (self *HeapOf[T]) CheckInvariant() {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  self.waiters.checkInvariant()
+  if len(self.items) != len(self.handles) { panic("invariant broken") }
+  for i, h := range self.handles {
+    if h.owner != self || h.index != i || h.seq >= self.seq { panic("invariant broken") }
+    if i > 0 && self.less(i, (i-1)/2) { panic("invariant broken") }
+  }
+  if len(self.items) == 0 && len(self.isEmpty) != 0 { panic("invariant broken") }
+  if len(self.items) != 0 && len(self.hasItem) != 0 { panic("invariant broken") }
+}
+
+func (self *HeapOf[T]) less(i, j int) bool {
+  c := self.cmp(self.items[i], self.items[j])
+  if c != 0 { return c < 0 }
+  return self.handles[i].seq < self.handles[j].seq
+}
+
+func (self *HeapOf[T]) swap(i, j int) {
+  self.items[i], self.items[j] = self.items[j], self.items[i]
+  self.handles[i], self.handles[j] = self.handles[j], self.handles[i]
+  self.handles[i].index = i
+  self.handles[j].index = j
+}
+
+// Moves items[i] towards the root as far as necessary.
+func (self *HeapOf[T]) up(i int) {
+  for i > 0 {
+    parent := (i-1)/2
+    if !self.less(i, parent) { break }
+    self.swap(i, parent)
+    i = parent
+  }
+}
+
+// Moves items[i] towards the leaves as far as necessary. Returns true
+// iff the item has been moved.
+func (self *HeapOf[T]) down(i int) bool {
+  i0 := i
+  n := len(self.items)
+  for {
+    child := 2*i+1
+    if child >= n { break }
+    if child+1 < n && self.less(child+1, child) { child++ }
+    if !self.less(child, i) { break }
+    self.swap(i, child)
+    i = child
+  }
+  return i > i0
+}
+
+// Removes and returns items[i] which must exist.
+func (self *HeapOf[T]) removeAt(i int) T {
+  n := len(self.items)-1
+  if i != n { self.swap(i, n) }
+  item := self.items[n]
+  h := self.handles[n]
+  var zero T
+  self.items[n] = zero // don't keep a reference for the garbage collector
+  self.handles[n] = nil
+  self.items = self.items[:n]
+  self.handles = self.handles[:n]
+  if i != n && !self.down(i) { self.up(i) }
+  h.owner = nil
+  h.index = -1
+  if n == 0 { self.signal(&self.isEmpty) }
+  return item
+}
@@ -0,0 +1,159 @@
+/* Copyright (C) 2012 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named iterator.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package deque
+
+import (
+          "fmt"
+          "iter"
+          "sync"
+       )
+
+// Flags for Iterator(). Combine them with "|" or pass them as separate arguments.
+const (
+  // Iterate from the stack top Peek(0) down to At(0) instead of from At(0)
+  // up to Peek(0).
+  REVERSE = 1 << iota
+  // Iterate over the live Deque instead of a snapshot. A live iterator does
+  // not copy the items but it locks the Deque on every step and stops with
+  // the error ConcurrentModification if the Deque has been changed in
+  // the meantime by an operation that adds, removes or reorders items.
+  // Replacing items with Put() or Poke() is not considered a modification.
+  LIVE
+)
+
+// A LIVE iterator stops and Err() returns this error if the Deque
+// has been modified since the iterator was created.
+var ConcurrentModification = fmt.Errorf("Deque modified during iteration")
+
+// Iterates over the items of a Deque or Of[T]. A new iterator is positioned
+// before the first item, so Next() needs to be called before Value(). Typical use:
+//
+//   it := d.Iterator(deque.LIVE)
+//   for it.Next() {
+//     fmt.Println(it.Index(), it.Value())
+//   }
+//   if it.Err() != nil { ... }
+//
+// An iterator is not meant to be used by multiple goroutines concurrently,
+// but the Deque it iterates over may be used concurrently by others.
+type IteratorOf[T any] struct {
+  mutex *sync.Mutex
+  // The Deque's ring buffer in LIVE mode, nil in snapshot mode.
+  ring *ring[T]
+  // In LIVE mode ring.mods at creation time.
+  mods uint
+  // In snapshot mode the copy of all items taken at creation time.
+  items []T
+  reverse bool
+  // Number of items visited so far.
+  visited int
+  // Index and value of the current item. index is -1 if there is none.
+  index int
+  value T
+  err error
+}
+
+// The interface{} version of IteratorOf[T] that is returned by Deque.Iterator().
+// It is implemented by *IteratorOf[interface{}].
+type Iterator interface{
+  // Advances to the next item and returns true, or returns false if there
+  // are no more items or an error occurred (see Err()).
+  Next() bool
+  // Returns the current item, i.e. the item At(Index()) at the time of the
+  // last Next() call. Returns nil if there is no current item.
+  Value() interface{}
+  // Returns the index of the current item as passed to At(), or -1 if
+  // Next() has not been called yet or the iteration has ended.
+  Index() int
+  // Returns nil or ConcurrentModification if a LIVE iteration has been
+  // stopped because the Deque was modified.
+  Err() error
+  // Returns an adapter for range-over-func loops that calls Next() and
+  // yields Value() for all remaining items.
+  Values() iter.Seq[interface{}]
+}
+
+// Creates an iterator over the ring buffer r protected by mutex.
+func newIterator[T any](mutex *sync.Mutex, r *ring[T], flags []int) *IteratorOf[T] {
+  f := 0
+  for _, x := range flags { f |= x }
+  it := &IteratorOf[T]{mutex: mutex, reverse: f & REVERSE != 0, index: -1}
+  mutex.Lock()
+  defer mutex.Unlock()
+  if f & LIVE != 0 {
+    it.ring = r
+    it.mods = r.mods
+  } else {
+    it.items = r.appendTo(make([]T, 0, r.count))
+  }
+  return it
+}
+
+// See Iterator.Next().
+func (self *IteratorOf[T]) Next() bool {
+  var zero T
+  self.value = zero
+  self.index = -1
+  if self.err != nil { return false }
+  
+  count := len(self.items)
+  if self.ring != nil {
+    self.mutex.Lock()
+    defer self.mutex.Unlock()
+    if self.ring.mods != self.mods {
+      self.err = ConcurrentModification
+      return false
+    }
+    count = self.ring.count
+  }
+  
+  if self.visited >= count { return false }
+  idx := self.visited
+  if self.reverse { idx = count-1-idx }
+  self.visited++
+  
+  self.index = idx
+  if self.ring != nil {
+    self.value, _ = self.ring.at(idx)
+  } else {
+    self.value = self.items[idx]
+  }
+  return true
+}
+
+// See Iterator.Value().
+func (self *IteratorOf[T]) Value() T { return self.value }
+
+// See Iterator.Index().
+func (self *IteratorOf[T]) Index() int { return self.index }
+
+// See Iterator.Err().
+func (self *IteratorOf[T]) Err() error { return self.err }
+
+// See Iterator.Values().
+func (self *IteratorOf[T]) Values() iter.Seq[T] {
+  return func(yield func(T) bool) {
+    for self.Next() {
+      if !yield(self.value) { return }
+    }
+  }
+}
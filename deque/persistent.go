@@ -0,0 +1,581 @@
+/* Copyright (C) 2012 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named persistent.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package deque
+
+import (
+          "bufio"
+          "context"
+          "encoding/binary"
+          "fmt"
+          "hash/crc32"
+          "io"
+          "os"
+          "path/filepath"
+          "sync"
+          "time"
+       )
+
+// A Deque whose contents survive the death of the process. Every change is
+// appended to a write-ahead log file before the function that made it returns.
+// When the file is opened again with OpenPersistentOf(), the log is replayed
+// to restore the Deque's contents. A record that was only partially written
+// because the process died while writing it is detected by its checksum and
+// discarded. If a change can not be logged, e.g. because the disk is full,
+// see Err().
+//
+// Only one Persistent at a time can use a log file. The file is locked
+// (where the operating system supports it) so that other processes can not
+// open it while it is in use.
+//
+// To keep the log from growing without bounds, it is compacted (i.e.
+// replaced with a log that contains only the current items) automatically
+// when it contains CompactAfter more records than items, or when you call
+// Compact().
+//
+// The methods have the same semantics as the respective Deque methods,
+// including Growth functions, blocking and waiting, so producer-consumer code
+// can switch from a Deque to a Persistent without changes.
+//
+// Example:
+//
+//   queue, err := deque.OpenPersistentOf("jobs.log", deque.JSONCodec[Job]{}, 1000, deque.BlockIfFull)
+//   if err != nil { ... }
+//   defer queue.Close()
+//   queue.Push(job)      // survives a crash once Push() returns
+//   ...
+//   job := queue.Next()
+type PersistentOf[T any] struct {
+  // See the documentation for the type GrowthFunc.
+  Growth GrowthFunc
+  // Counts the number of times Growth() has been called.
+  GrowthCount uint
+  // If true, every change is followed by a call to Sync() on the log file,
+  // so that it survives not only the death of the process but also a crash
+  // of the operating system. This is much slower.
+  Sync bool
+  // If the log contains at least CompactAfter more records than there are
+  // items in the Deque, it is compacted. 0 disables automatic compaction.
+  // The default is 1000.
+  CompactAfter int
+  // The Mutex that protects this Deque against concurrent access.
+  // You must lock it before changing any of the above fields.
+  Mutex sync.Mutex
+  // The ring buffer holding the items, and the lists of waiters.
+  ring[T]
+  codec Codec[T]
+  path string
+  // The log file, opened for appending.
+  file *os.File
+  // The number of records in the log file.
+  records int
+  // The error that prevented the last change from being logged. While
+  // err != nil, nothing is appended to the log. Instead every change tries
+  // to compact(). See Err().
+  err error
+}
+
+// The interface{} version of PersistentOf[T].
+type Persistent = PersistentOf[interface{}]
+
+// Opens (or creates) the log file at path and replays it.
+// See OpenPersistentOf().
+func OpenPersistent(path string, codec Codec[interface{}], args... interface{}) (*Persistent, error) {
+  return OpenPersistentOf(path, codec, args...)
+}
+
+// Opens (or creates) the log file at path and replays it to restore the
+// Deque's contents. codec is used to convert the items to and from bytes.
+// args may contain an initial capacity and a GrowthFunc as for Deque.Init().
+// If the log contains more items than the capacity, the capacity is
+// increased to fit.
+//
+// A partially written record at the end of the log is removed from the file.
+// If the log contains a corrupt record that is not at the end or a complete
+// record that can not be replayed (e.g. because codec can not decode it),
+// an error is returned and the file is left unchanged.
+// An error is also returned if the file is in use by another process.
+func OpenPersistentOf[T any](path string, codec Codec[T], args... interface{}) (*PersistentOf[T], error) {
+  self := &PersistentOf[T]{codec: codec, path: path, CompactAfter: 1000, Growth: GrowthDefault}
+  requested_capacity := int(CapacityDefault)
+  for i, x := range args {
+    switch arg := x.(type) {
+      case int:  requested_capacity = arg
+      case uint: requested_capacity = int(arg)
+      case int64: requested_capacity = int(arg)
+      case uint64: requested_capacity = int(arg)
+      case GrowthFunc: self.Growth = arg
+      case func(uint, uint, uint) uint: self.Growth = arg
+      default: return nil, fmt.Errorf("Argument #%d is unsupported by deque.OpenPersistentOf()",i+3)
+    }
+  }
+  
+  var err error
+  self.file, err = openLog(path, os.O_RDWR|os.O_CREATE|os.O_APPEND)
+  if err != nil { return nil, err }
+  data, err := io.ReadAll(self.file)
+  valid := 0
+  if err == nil { valid, err = self.replay(data) }
+  if err == nil && valid < len(data) { // discard the torn tail
+    err = self.file.Truncate(int64(valid))
+  }
+  if err != nil {
+    self.file.Close()
+    return nil, err
+  }
+  
+  remaining := requested_capacity - self.count
+  if remaining < 0 { remaining = 0 }
+  self.overcapacity(uint(remaining))
+  return self, nil
+}
+
+// Returns nil if all changes have been logged. Otherwise returns the error
+// that prevented a change from being logged (e.g. a full disk or an item the
+// Codec can not encode). That change has only been made in memory. Because the log may end in a partially written
+// record, nothing is appended to it anymore. Instead every following change
+// (and Compact()) tries to replace the log with a fresh one that contains all
+// current items. The first attempt that succeeds clears the error.
+func (self *PersistentOf[T]) Err() error {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.err
+}
+
+// Replaces the log with one that contains only the current items. The new log is
+// written to a temporary file that is renamed to the log's name when
+// complete, so that a crash during compaction does not lose anything.
+// If an error occurs, the old log remains in use and the error is returned.
+func (self *PersistentOf[T]) Compact() error {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.compact()
+}
+
+// Compacts the log and closes it. After that the Deque must not be used anymore.
+func (self *PersistentOf[T]) Close() error {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.file == nil { return os.ErrClosed }
+  err := self.compact()
+  if err2 := self.file.Close(); err == nil { err = err2 }
+  self.file = nil
+  self.err = os.ErrClosed
+  return err
+}
+
+// See Deque.Count().
+func (self *PersistentOf[T]) Count() int {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.count
+}
+
+// See Deque.Capacity().
+func (self *PersistentOf[T]) Capacity() int {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return len(self.data)
+}
+
+// See Deque.IsEmpty().
+func (self *PersistentOf[T]) IsEmpty() bool { return self.Count()==0 }
+
+// See Deque.IsFull().
+func (self *PersistentOf[T]) IsFull() bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return len(self.data) == self.count
+}
+
+// See Deque.WaitForItem().
+func (self *PersistentOf[T]) WaitForItem(timeout time.Duration) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.count > 0 { return true }
+  return self.waitFor(&self.Mutex, &self.hasItem, timeout)
+}
+
+// See Deque.WaitForSpace().
+func (self *PersistentOf[T]) WaitForSpace(timeout time.Duration) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.count < len(self.data) { return true }
+  return self.waitFor(&self.Mutex, &self.hasSpace, timeout)
+}
+
+// See Deque.WaitForEmpty().
+func (self *PersistentOf[T]) WaitForEmpty(timeout time.Duration) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if self.count == 0 { return true }
+  return self.waitFor(&self.Mutex, &self.isEmpty, timeout)
+}
+
+// See Deque.WaitForItemCtx().
+func (self *PersistentOf[T]) WaitForItemCtx(ctx context.Context) error {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.waitForItemCtx(ctx)
+}
+
+// See Deque.Push().
+//
+// If the item can not be encoded by the Codec, it is added nevertheless
+// but Err() returns the Codec's error until the item has been removed
+// again (see Err()).
+func (self *PersistentOf[T]) Push(item T) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.insert(func() int { return self.count }, item)
+}
+
+// See Deque.PushAt() and Push().
+func (self *PersistentOf[T]) PushAt(idx int, item T) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.insert(func() int { return self.count-idx }, item)
+}
+
+// See Deque.Insert() and Push().
+func (self *PersistentOf[T]) Insert(item T) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.insert(func() int { return 0 }, item)
+}
+
+// See Deque.InsertAt() and Push().
+func (self *PersistentOf[T]) InsertAt(idx int, item T) bool {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return self.insert(func() int { return idx }, item)
+}
+
+// See Deque.Pop().
+func (self *PersistentOf[T]) Pop() T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  for ; self.count == 0 ; {
+    self.waitFor(&self.Mutex, &self.hasItem, 0)
+  }
+  item, _ := self.remove(self.count-1)
+  return item
+}
+
+// See Deque.Next().
+func (self *PersistentOf[T]) Next() T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  for ; self.count == 0 ; {
+    self.waitFor(&self.Mutex, &self.hasItem, 0)
+  }
+  item, _ := self.remove(0)
+  return item
+}
+
+// See Deque.NextCtx().
+func (self *PersistentOf[T]) NextCtx(ctx context.Context) (item T, err error) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if err = self.waitForItemCtx(ctx); err != nil { return item, err }
+  item, _ = self.remove(0)
+  return item, nil
+}
+
+// See Deque.PopCtx().
+func (self *PersistentOf[T]) PopCtx(ctx context.Context) (item T, err error) {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  if err = self.waitForItemCtx(ctx); err != nil { return item, err }
+  item, _ = self.remove(self.count-1)
+  return item, nil
+}
+
+// See Deque.PopAt(). Returns the zero value if idx is out of range.
+func (self *PersistentOf[T]) PopAt(idx int) T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  item, _ := self.remove(self.count-1-idx)
+  return item
+}
+
+// See Deque.RemoveAt(). Returns the zero value if idx is out of range.
+func (self *PersistentOf[T]) RemoveAt(idx int) T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  item, _ := self.remove(idx)
+  return item
+}
+
+// See Deque.At(). Returns the zero value if idx is out of range.
+func (self *PersistentOf[T]) At(idx int) T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  item, _ := self.at(idx)
+  return item
+}
+
+// See Deque.Peek(). Returns the zero value if idx is out of range.
+func (self *PersistentOf[T]) Peek(idx int) T {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  item, _ := self.at(self.count-1-idx)
+  return item
+}
+
+// Removes all items. The capacity remains unchanged. Returns the Deque.
+func (self *PersistentOf[T]) Clear() *PersistentOf[T] {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  self.reset(make([]T, 0, len(self.data)))
+  self.log(op_clear, 0, nil)
+  return self
+}
+
+// See Deque.CheckInvariant().
+func (self *PersistentOf[T]) CheckInvariant() {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  self.ring.checkInvariant()
+  if self.Growth == nil { panic("invariant broken") }
+}
+
+// Returns a string representation of the Deque.
+func (self *PersistentOf[T]) String() string {
+  self.Mutex.Lock()
+  defer self.Mutex.Unlock()
+  return fmt.Sprintf("Deque%v", self.appendTo(nil))
+}
+
+// Log record types.
+const (
+  op_insert = 1 // insertAt(idx, payload)
+  op_remove = 2 // removeAt(idx)
+  op_clear  = 3 // remove all items
+)
+
+// Size of a record's header: 4 bytes body length, 4 bytes CRC32 of the body.
+// The body consists of the op byte, idx as uvarint and the payload.
+const record_header = 8
+
+// Appends the record to buf and returns the result.
+func appendRecord(buf []byte, op byte, idx int, payload []byte) []byte {
+  body := make([]byte, 0, 1+binary.MaxVarintLen64+len(payload))
+  body = append(body, op)
+  body = binary.AppendUvarint(body, uint64(idx))
+  body = append(body, payload...)
+  buf = binary.LittleEndian.AppendUint32(buf, uint32(len(body)))
+  buf = binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(body))
+  return append(buf, body...)
+}
+
+// Applies all records in data to the (empty) Deque. Stops at a torn tail,
+// i.e. a last record that is incomplete or has a wrong checksum, or a tail
+// that consists only of 0 bytes (which some file systems leave behind after
+// a crash). This can only be the result of a write that was interrupted.
+// Returns the number of bytes successfully replayed.
+// A bad record that is followed by more data, or a record with a correct
+// checksum that can not be applied means that the log is not what we expect
+// (e.g. it is corrupt or was written with a different Codec). In that case
+// an error is returned.
+func (self *PersistentOf[T]) replay(data []byte) (int, error) {
+  pos := 0
+  var growthcount uint
+  for pos < len(data) {
+    if isZero(data[pos:]) { break }
+    rest := len(data) - pos - record_header
+    if rest < 0 { break }
+    n := int(binary.LittleEndian.Uint32(data[pos:]))
+    crc := binary.LittleEndian.Uint32(data[pos+4:])
+    if n > rest { break }
+    body := data[pos+record_header : pos+record_header+n]
+    if n < 1 || crc32.ChecksumIEEE(body) != crc {
+      if n == rest { break }
+      return pos, fmt.Errorf("Corrupt record at offset %v of %v", pos, self.path)
+    }
+    var err error
+    idx, l := binary.Uvarint(body[1:])
+    if l <= 0 {
+      err = fmt.Errorf("Invalid index")
+    } else {
+      payload := body[1+l:]
+      switch body[0] {
+        case op_insert:
+          var item T
+          item, err = self.codec.Decode(payload)
+          if err == nil && self.insertAt(int(idx), item, Double, &growthcount) <= 0 {
+            err = fmt.Errorf("Index %v out of range", idx)
+          }
+        case op_remove:
+          if _, ok := self.removeAt(int(idx)); !ok {
+            err = fmt.Errorf("Index %v out of range", idx)
+          }
+        case op_clear:
+          self.reset(make([]T, 0, len(self.data)))
+        default:
+          err = fmt.Errorf("Unknown operation %v", body[0])
+      }
+    }
+    if err != nil {
+      return pos, fmt.Errorf("Cannot replay record at offset %v of %v: %v", pos, self.path, err)
+    }
+    pos += record_header + n
+    self.records++
+  }
+  return pos, nil
+}
+
+// Returns true if all bytes of data are 0.
+func isZero(data []byte) bool {
+  for _, b := range data {
+    if b != 0 { return false }
+  }
+  return true
+}
+
+// Like Of[T].insert() but logs the insertion. The caller must hold the Mutex.
+// If the item can not be encoded, it is inserted anyway and the error is
+// treated like a failed write (see Err()).
+func (self *PersistentOf[T]) insert(idx func() int, item T) bool {
+  payload, encode_err := self.codec.Encode(item)
+  for {
+    i := idx()
+    count := self.count
+    res := self.insertAt(i, item, self.Growth, &self.GrowthCount)
+    if res < 0 { return false }
+    if res > 0 {
+      if encode_err != nil {
+        self.err = encode_err
+      } else {
+        self.log(op_insert, i, payload)
+      }
+      if self.count == count { // Growth() returned DROP_FAR_END
+        // Log the removal the same way insertAt() did it.
+        if i+i > count {
+          self.log(op_remove, 0, nil)
+        } else {
+          self.log(op_remove, count, nil)
+        }
+      }
+      return true
+    }
+    self.waitFor(&self.Mutex, &self.hasSpace, 0)
+  }
+}
+
+// removeAt() with logging. The caller must hold the Mutex.
+func (self *PersistentOf[T]) remove(idx int) (item T, ok bool) {
+  item, ok = self.removeAt(idx)
+  if ok { self.log(op_remove, idx, nil) }
+  return item, ok
+}
+
+// Appends a record to the log and compacts it if necessary.
+// If a previous change could not be logged, the log is compacted instead,
+// which writes all items, including the one affected by this change.
+func (self *PersistentOf[T]) log(op byte, idx int, payload []byte) {
+  if self.err != nil {
+    self.compact()
+    return
+  }
+  _, err := self.file.Write(appendRecord(nil, op, idx, payload))
+  if err == nil && self.Sync { err = self.file.Sync() }
+  if err != nil {
+    // The log may now end in a partial record, so we must not append to it.
+    self.err = err
+    return
+  }
+  self.records++
+  if self.CompactAfter > 0 && self.records - self.count >= self.CompactAfter {
+    // If this fails, the old log remains valid and we'll try again next time.
+    self.compact()
+  }
+}
+
+// Implements Compact(). The caller must hold the Mutex.
+func (self *PersistentOf[T]) compact() error {
+  if self.file == nil { return os.ErrClosed }
+  tmp := self.path + ".compact"
+  // The new log is locked before it becomes visible under self.path, so
+  // that no other process can get hold of it.
+  f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0666)
+  if err != nil { return err }
+  if err = lockFile(f); err != nil {
+    f.Close()
+    return err
+  }
+  w := bufio.NewWriter(f)
+  for i := 0; i < self.count && err == nil; i++ {
+    var payload []byte
+    payload, err = self.codec.Encode(self.data[self.index(i)])
+    if err == nil { _, err = w.Write(appendRecord(nil, op_insert, i, payload)) }
+  }
+  if err == nil { err = w.Flush() }
+  if err == nil { err = f.Sync() }
+  if err == nil { err = os.Rename(tmp, self.path) }
+  if err != nil {
+    f.Close()
+    os.Remove(tmp)
+    return err
+  }
+  
+  // Make the rename durable. Not all systems support this, so errors are ignored.
+  if dir, err := os.Open(filepath.Dir(self.path)); err == nil {
+    dir.Sync()
+    dir.Close()
+  }
+  
+  self.file.Close()
+  self.file = f
+  self.records = self.count
+  self.err = nil
+  return nil
+}
+
+// Opens the log file at path with the given flags and locks it.
+// Because compact() replaces the log by renaming a new file over it, the file
+// may no longer be the log by the time we get the lock. In that case the
+// new log is opened.
+func openLog(path string, flags int) (*os.File, error) {
+  for {
+    f, err := os.OpenFile(path, flags, 0666)
+    if err != nil { return nil, err }
+    err = lockFile(f)
+    var locked, current os.FileInfo
+    if err == nil { locked, err = f.Stat() }
+    if err == nil { current, err = os.Stat(path) }
+    if err != nil {
+      f.Close()
+      return nil, err
+    }
+    if os.SameFile(locked, current) { return f, nil }
+    f.Close()
+  }
+}
+
+// The caller is responsible for locking self.
+func (self *PersistentOf[T]) waitForItemCtx(ctx context.Context) error {
+  if err := ctx.Err(); err != nil { return err }
+  for ; self.count == 0 ; {
+    if err := self.waitForCtx(&self.Mutex, &self.hasItem, ctx); err != nil { return err }
+  }
+  return nil
+}
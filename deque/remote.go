@@ -0,0 +1,497 @@
+/* Copyright (C) 2012 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named remote.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package deque
+
+import (
+          "context"
+          "encoding/binary"
+          "fmt"
+          "io"
+          "net"
+          "os"
+          "sync"
+          "time"
+       )
+
+/*********************************************************************************
+
+                   INTER-PROCESS DEQUES
+
+*********************************************************************************/
+
+// A Server makes Deques available to other processes, typically via a
+// Unix domain socket. Each exported Deque is an Of[[]byte] that holds the
+// items as encoded by the clients' Codec, so the server does not need to
+// know the item type. The exporting process can use the Deque directly by
+// encoding and decoding the items itself, e.g. with the same Codec.
+//
+// Example:
+//
+//   // server process
+//   server := deque.NewServer()
+//   server.Export("jobs", deque.NewOf[[]byte](100, deque.BlockIfFull))
+//   go server.ServeUnix("/run/myapp.sock")
+//
+//   // client processes
+//   jobs, err := deque.DialOf("/run/myapp.sock", "jobs", deque.JSONCodec[Job]{})
+//   jobs.Push(job)
+//   ...
+//   job := jobs.Next()
+type Server struct {
+  // The maximum size in bytes of a message (i.e. an encoded item) a client
+  // may send. A client that sends a larger message gets an error and is
+  // disconnected. Changes only affect connections accepted afterwards.
+  // NewServer() sets this to MaxMessageSizeDefault.
+  MaxMessageSize int
+  mutex sync.Mutex
+  deques map[string]*Of[[]byte]
+  listeners map[net.Listener]bool
+  conns map[net.Conn]bool
+  closed bool
+}
+
+// A client's connection to a Deque exported by a Server. It has the same
+// methods and semantics as Of[T] for the supported operations. Blocking
+// operations are performed on the server, so e.g. Next() on a client blocks
+// until any process pushes an item.
+//
+// If the connection fails, the methods return the zero value (or false)
+// and Err() returns the error.
+//
+// Items are delivered at most once. When the server removes an item for a
+// client's Next() or Pop() and the connection breaks before the client has
+// received it, the item is lost, unless the server notices the broken
+// connection while sending the item. In that case it returns the item to the
+// Deque.
+// A ClientOf[T] is goroutine-safe, but
+// operations are executed one at a time, so while one goroutine is blocked
+// in Next(), other goroutines' calls will wait. Use separate clients for
+// concurrent blocking operations.
+type ClientOf[T any] struct {
+  // The maximum size in bytes of a reply (e.g. an encoded item) the client
+  // accepts from the server. A larger reply makes the operation fail and
+  // breaks the connection (see Err()). NewClientOf() and DialOf() set this to
+  // MaxMessageSizeDefault. Must not be changed while the client is in use.
+  MaxMessageSize int
+  mutex sync.Mutex
+  conn net.Conn
+  codec Codec[T]
+  err error
+}
+
+// The interface{} version of ClientOf[T].
+type Client = ClientOf[interface{}]
+
+// Message types. A message consists of 4 bytes length (little endian) of the
+// payload, 1 byte type and the payload.
+const (
+  // client -> server
+  msg_hello = 1 // payload: name of the Deque. Must be the 1st message.
+  msg_push  = 2 // payload: item
+  msg_next  = 3
+  msg_pop   = 4
+  msg_count = 5
+  msg_wait  = 6 // WaitForItem
+  msg_cancel = 7 // cancels the current operation. Has no reply of its own.
+  
+  // server -> client
+  msg_ok = 100    // payload depends on request
+  msg_cancelled = 101 // the request was cancelled before it completed
+  msg_error = 102 // payload: error message
+)
+
+// The default for Server.MaxMessageSize and ClientOf.MaxMessageSize.
+var MaxMessageSizeDefault = 16 << 20
+
+// Creates a Server that exports no Deques.
+func NewServer() *Server {
+  return &Server{MaxMessageSize: MaxMessageSizeDefault, deques: map[string]*Of[[]byte]{}, listeners: map[net.Listener]bool{}, conns: map[net.Conn]bool{}}
+}
+
+// Makes d available to clients under the given name, replacing the Deque
+// previously exported under that name (if any). Existing connections are not
+// affected by the replacement.
+func (self *Server) Export(name string, d *Of[[]byte]) {
+  self.mutex.Lock()
+  defer self.mutex.Unlock()
+  self.deques[name] = d
+}
+
+// Creates a Unix domain socket at path and calls Serve() with it.
+// If a socket that nobody listens on exists at path (e.g. left over by a
+// crashed server), it is replaced. If another server listens on it, an
+// error is returned. Other kinds of files are never replaced.
+func (self *Server) ServeUnix(path string) error {
+  if fi, err := os.Lstat(path); err == nil && fi.Mode() & os.ModeSocket != 0 {
+    conn, err := net.Dial("unix", path)
+    if err == nil {
+      conn.Close()
+      return fmt.Errorf("%v is in use by another server", path)
+    }
+    if isConnRefused(err) { os.Remove(path) }
+  }
+  l, err := net.Listen("unix", path)
+  if err != nil { return err }
+  return self.Serve(l)
+}
+
+// Accepts client connections on l and serves each of them in its own
+// goroutine. Does not return until l fails or the Server is Close()d.
+// After Close() the return value is nil.
+func (self *Server) Serve(l net.Listener) error {
+  self.mutex.Lock()
+  if self.closed { 
+    self.mutex.Unlock()
+    l.Close()
+    return nil
+  }
+  self.listeners[l] = true
+  self.mutex.Unlock()
+  
+  for {
+    conn, err := l.Accept()
+    if err != nil {
+      self.mutex.Lock()
+      defer self.mutex.Unlock()
+      delete(self.listeners, l)
+      if self.closed { return nil }
+      return err
+    }
+    go self.serve(conn)
+  }
+}
+
+// Closes all listeners and connections. Operations in progress on behalf of
+// clients are cancelled. The exported Deques remain unchanged.
+func (self *Server) Close() error {
+  self.mutex.Lock()
+  defer self.mutex.Unlock()
+  self.closed = true
+  for l := range self.listeners { l.Close() }
+  for c := range self.conns { c.Close() }
+  return nil
+}
+
+// Connects to the Deque exported by a Server under name via the Unix domain
+// socket at path. See DialOf().
+func Dial(path string, name string, codec Codec[interface{}]) (*Client, error) {
+  return DialOf(path, name, codec)
+}
+
+// Connects to the Deque exported by a Server under name via the Unix domain
+// socket at path. The items are encoded with codec.
+func DialOf[T any](path string, name string, codec Codec[T]) (*ClientOf[T], error) {
+  conn, err := net.Dial("unix", path)
+  if err != nil { return nil, err }
+  return NewClientOf(conn, name, codec)
+}
+
+// Like DialOf() but uses an existing connection to a Server. This allows
+// using transports other than Unix domain sockets.
+func NewClientOf[T any](conn net.Conn, name string, codec Codec[T]) (*ClientOf[T], error) {
+  self := &ClientOf[T]{MaxMessageSize: MaxMessageSizeDefault, conn: conn, codec: codec}
+  if _, err := self.call(context.Background(), msg_hello, []byte(name)); err != nil {
+    conn.Close()
+    return nil, err
+  }
+  return self, nil
+}
+
+// Returns nil or the error that broke the connection.
+func (self *ClientOf[T]) Err() error {
+  self.mutex.Lock()
+  defer self.mutex.Unlock()
+  return self.err
+}
+
+// Closes the connection. This also makes operations blocked in other
+// goroutines return. After that the client must not be used anymore.
+func (self *ClientOf[T]) Close() error {
+  return self.conn.Close()
+}
+
+// See Deque.Push().
+// Panics if the item can not be encoded by the Codec.
+func (self *ClientOf[T]) Push(item T) bool {
+  ok, _ := self.PushCtx(context.Background(), item)
+  return ok
+}
+
+// See Deque.PushCtx() and Push().
+func (self *ClientOf[T]) PushCtx(ctx context.Context, item T) (bool, error) {
+  payload, err := self.codec.Encode(item)
+  if err != nil { panic(err) }
+  reply, err := self.call(ctx, msg_push, payload)
+  if err != nil { return false, err }
+  return len(reply) == 1 && reply[0] == 1, nil
+}
+
+// See Deque.Next().
+func (self *ClientOf[T]) Next() T {
+  item, _ := self.NextCtx(context.Background())
+  return item
+}
+
+// See Deque.NextCtx().
+func (self *ClientOf[T]) NextCtx(ctx context.Context) (item T, err error) {
+  return self.remove(ctx, msg_next)
+}
+
+// See Deque.Pop().
+func (self *ClientOf[T]) Pop() T {
+  item, _ := self.PopCtx(context.Background())
+  return item
+}
+
+// See Deque.PopCtx().
+func (self *ClientOf[T]) PopCtx(ctx context.Context) (item T, err error) {
+  return self.remove(ctx, msg_pop)
+}
+
+// See Deque.Count(). Returns 0 if the connection has failed.
+func (self *ClientOf[T]) Count() int {
+  reply, err := self.call(context.Background(), msg_count, nil)
+  if err != nil { return 0 }
+  n, _ := binary.Uvarint(reply)
+  return int(n)
+}
+
+// See Deque.IsEmpty().
+func (self *ClientOf[T]) IsEmpty() bool { return self.Count()==0 }
+
+// See Deque.WaitForItem(). Returns false if the connection has failed.
+func (self *ClientOf[T]) WaitForItem(timeout time.Duration) bool {
+  ctx := context.Background()
+  if timeout > 0 {
+    var cancel context.CancelFunc
+    ctx, cancel = context.WithTimeout(ctx, timeout)
+    defer cancel()
+  }
+  return self.WaitForItemCtx(ctx) == nil
+}
+
+// See Deque.WaitForItemCtx().
+func (self *ClientOf[T]) WaitForItemCtx(ctx context.Context) error {
+  _, err := self.call(ctx, msg_wait, nil)
+  return err
+}
+
+// Implements NextCtx() and PopCtx().
+func (self *ClientOf[T]) remove(ctx context.Context, msg byte) (item T, err error) {
+  reply, err := self.call(ctx, msg, nil)
+  if err != nil { return item, err }
+  item, err = self.codec.Decode(reply)
+  return item, err
+}
+
+// Sends a request and waits for the reply. If ctx is done before the
+// reply arrives, the server is told to cancel the request. Returns the
+// reply's payload or the error. Returns ctx.Err() if the request was cancelled.
+func (self *ClientOf[T]) call(ctx context.Context, msg byte, payload []byte) ([]byte, error) {
+  self.mutex.Lock()
+  defer self.mutex.Unlock()
+  if self.err != nil { return nil, self.err }
+  if err := ctx.Err(); err != nil { return nil, err }
+  
+  if err := writeMessage(self.conn, msg, payload); err != nil { return nil, self.fail(err) }
+  
+  // While waiting for the reply, send msg_cancel if ctx is done.
+  var wg sync.WaitGroup
+  done := make(chan bool)
+  var cancel_err error
+  wg.Add(1)
+  go func() {
+    defer wg.Done()
+    select {
+      case <-ctx.Done(): cancel_err = writeMessage(self.conn, msg_cancel, nil)
+      case <-done:
+    }
+  }()
+  reply_msg, reply, err := readMessage(self.conn, self.MaxMessageSize)
+  close(done)
+  wg.Wait()
+  if err == nil { err = cancel_err }
+  if err != nil { return nil, self.fail(err) }
+  
+  switch reply_msg {
+    case msg_ok: return reply, nil
+    case msg_cancelled: 
+      if err := ctx.Err(); err != nil { return nil, err }
+      return nil, context.Canceled
+    case msg_error: return nil, fmt.Errorf("%s", reply)
+  }
+  return nil, self.fail(fmt.Errorf("Unknown reply type %d from deque server", reply_msg))
+}
+
+// Marks the connection as broken. Returns err.
+func (self *ClientOf[T]) fail(err error) error {
+  self.err = err
+  self.conn.Close()
+  return err
+}
+
+// Serves a single client connection.
+func (self *Server) serve(conn net.Conn) {
+  self.mutex.Lock()
+  if self.closed {
+    self.mutex.Unlock()
+    conn.Close()
+    return
+  }
+  self.conns[conn] = true
+  max := self.MaxMessageSize
+  self.mutex.Unlock()
+  defer func() {
+    self.mutex.Lock()
+    delete(self.conns, conn)
+    self.mutex.Unlock()
+    conn.Close()
+  }()
+  
+  msg, name, err := readMessage(conn, max)
+  if err != nil || msg != msg_hello { return }
+  self.mutex.Lock()
+  d := self.deques[string(name)]
+  self.mutex.Unlock()
+  if d == nil {
+    writeMessage(conn, msg_error, []byte(fmt.Sprintf("Unknown Deque '%s'", name)))
+    return
+  }
+  if writeMessage(conn, msg_ok, nil) != nil { return }
+  
+  // Requests are read by a separate goroutine, so that msg_cancel can be
+  // received while a request is blocked. Each request gets its own context,
+  // created by the reader before the request is passed on, so that a
+  // msg_cancel following the request can not be missed.
+  type request struct {
+    msg byte
+    payload []byte
+    ctx context.Context
+    cancel context.CancelFunc
+  }
+  connctx, cancelconn := context.WithCancel(context.Background())
+  defer cancelconn()
+  requests := make(chan request)
+  var mutex sync.Mutex
+  var current context.CancelFunc
+  // A message that was too large. Set by the reader before it closes requests.
+  var too_large error
+  go func() {
+    defer close(requests)
+    defer cancelconn() // connection is gone => cancel blocked requests
+    for {
+      msg, payload, err := readMessage(conn, max)
+      if err != nil {
+        if _, ok := err.(*messageTooLarge); ok { too_large = err }
+        return
+      }
+      if msg == msg_cancel {
+        mutex.Lock()
+        if current != nil { current() }
+        mutex.Unlock()
+        continue
+      }
+      ctx, cancel := context.WithCancel(connctx)
+      mutex.Lock()
+      current = cancel
+      mutex.Unlock()
+      select {
+        case requests <- request{msg, payload, ctx, cancel}:
+        case <-connctx.Done(): cancel(); return
+      }
+    }
+  }()
+  
+  for req := range requests {
+    var reply []byte
+    var err error
+    switch req.msg {
+      case msg_push:
+        var ok bool
+        ok, err = d.PushCtx(req.ctx, req.payload)
+        if ok { reply = []byte{1} } else { reply = []byte{0} }
+      case msg_next:
+        reply, err = d.NextCtx(req.ctx)
+      case msg_pop:
+        reply, err = d.PopCtx(req.ctx)
+      case msg_count:
+        reply = binary.AppendUvarint(nil, uint64(d.Count()))
+      case msg_wait:
+        err = d.WaitForItemCtx(req.ctx)
+      default:
+        err = fmt.Errorf("Unknown request type %d", req.msg)
+    }
+    req.cancel()
+    
+    reply_msg := byte(msg_ok)
+    if err == context.Canceled || err == context.DeadlineExceeded {
+      reply_msg = msg_cancelled
+      reply = nil
+    } else if err != nil {
+      reply_msg = msg_error
+      reply = []byte(err.Error())
+    }
+    if writeMessage(conn, reply_msg, reply) != nil {
+      // Don't lose the item the client did not receive.
+      if err == nil {
+        switch req.msg {
+          case msg_next: d.Insert(reply)
+          case msg_pop: d.Push(reply)
+        }
+      }
+      return
+    }
+  }
+  
+  // The client waits for the reply to the message that was too large.
+  if too_large != nil { writeMessage(conn, msg_error, []byte(too_large.Error())) }
+}
+
+// Returned by readMessage() for a message that exceeds the size limit.
+// The message's payload has not been read.
+type messageTooLarge struct {
+  size uint32
+  max int
+}
+
+func (self *messageTooLarge) Error() string {
+  return fmt.Sprintf("Message of %d bytes exceeds the limit of %d bytes", self.size, self.max)
+}
+
+func writeMessage(w io.Writer, msg byte, payload []byte) error {
+  buf := make([]byte, 5, 5+len(payload))
+  binary.LittleEndian.PutUint32(buf, uint32(len(payload)))
+  buf[4] = msg
+  _, err := w.Write(append(buf, payload...))
+  return err
+}
+
+// Reads a message whose payload may have at most max bytes (0 means no limit).
+func readMessage(r io.Reader, max int) (msg byte, payload []byte, err error) {
+  var header [5]byte
+  if _, err = io.ReadFull(r, header[:]); err != nil { return 0, nil, err }
+  size := binary.LittleEndian.Uint32(header[:])
+  if max > 0 && uint64(size) > uint64(max) { return 0, nil, &messageTooLarge{size, max} }
+  payload = make([]byte, size)
+  if _, err = io.ReadFull(r, payload); err != nil { return 0, nil, err }
+  return header[4], payload, nil
+}
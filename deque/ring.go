@@ -0,0 +1,582 @@
+/* Copyright (C) 2012 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named ring.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package deque
+
+import (
+          "context"
+          "slices"
+          "sync"
+          "time"
+       )
+
+// The lists of goroutines waiting for a Deque's state to change. None of the
+// functions in this file lock anything. The caller is responsible for holding
+// the respective Mutex.
+type waiters struct {
+  // The following 3 slices are used for waiting for the respective conditions.
+  // A waiter will create a buffered channel and append it to the respective list,
+  // then wait for a signal on that channel.
+  hasItem []chan bool
+  isEmpty []chan bool
+  hasSpace []chan bool
+}
+
+// The ring buffer shared by Deque and Of[T].
+type ring[T any] struct {
+  waiters
+  data []T
+  // Current item count. Not to be confused with capacity (which is len(data)).
+  count int
+  // Index of the element At(0). The next Insert() call will write to
+  // (a-1+capacity) % capacity .
+  // a==b occurs if the Deque is either empty or full. count distinguishes these cases.
+  a int
+  // Index that the next Push() will write to (if the Deque is not full).
+  // Peek(0) returns the item at index (b-1+capacity) % capacity.
+  //  If a<b, then the slice data[a:b] contains all items in order.
+  //  If a>b, then data[a:] is the 1st and data[:b] the 2nd part.
+  //  If a==b and count == len(data), then data[a:] is the 1st and data[:b] the 2nd part.
+  //  If a==b and count == 0, then data[a:b] (an empty slice) contains all items.
+  // Combined that is:
+  //  If a<b || count == 0, all items are found in data[a:b]
+  //  Otherwise all items are found in data[a:] followed by data[:b]
+  b int
+  // Incremented by every operation that adds, removes or reorders items.
+  // Used by live iterators to detect concurrent modification.
+  mods uint
+}
+
+// Wakes up all waiters in the list *what and clears the list.
+func (self *waiters) signal(what *[]chan bool) {
+  for _,c := range *what { c <- true } 
+  *what = (*what)[0:0]
+}
+
+// Appends a waiter to *what, unlocks mutex, waits for a signal or the timeout
+// (0 means no timeout) and locks mutex again.
+// Returns true if signalled, false on timeout.
+func (self *waiters) waitFor(mutex *sync.Mutex, what *[]chan bool, timeout time.Duration) bool {
+  c := make(chan bool, 2)
+  *what = append(*what, c)
+  mutex.Unlock()
+  defer mutex.Lock()
+  if timeout > 0 {
+    go func(){
+      time.Sleep(timeout)
+      c <- false
+    }()
+  }
+  return <-c // wait for signal or timeout
+}
+
+// Like waitFor() but waits until ctx is done instead of a timeout.
+// Returns nil if signalled and ctx.Err() if ctx is done. In the latter case
+// the waiter is removed from *what again.
+func (self *waiters) waitForCtx(mutex *sync.Mutex, what *[]chan bool, ctx context.Context) error {
+  c := make(chan bool, 2)
+  *what = append(*what, c)
+  mutex.Unlock()
+  select {
+    case <-c: mutex.Lock(); return nil
+    case <-ctx.Done(): mutex.Lock()
+  }
+  self.unregister(what, c)
+  return ctx.Err()
+}
+
+// Removes the waiter c from *what, unless a signal has already removed it.
+func (self *waiters) unregister(what *[]chan bool, c chan bool) {
+  for i, x := range *what {
+    if x == c {
+      *what = append((*what)[:i], (*what)[i+1:]...)
+      return
+    }
+  }
+}
+
+// Panics if a channel in one of the lists is not a fresh waiter channel.
+func (self *waiters) checkInvariant() {
+  for _, ar := range []*[]chan bool{&self.hasItem,&self.isEmpty,&self.hasSpace} {
+    for _, c := range *ar {
+      if len(c) != 0 || cap(c) != 2 { panic("invariant broken") }
+    }
+  }
+}
+
+// Replaces the buffer with items[0:cap(items)], with items[0:len(items)]
+// being the new contents, and notifies waiters based on the new state.
+func (self *ring[T]) reset(items []T) {
+  self.count = len(items)
+  self.a = 0
+  self.b = self.count
+  
+  // grow items slice to full capacity
+  self.data = items[0:cap(items)]
+  // wrap around b if beyond end
+  if self.b == len(self.data) { self.b = 0 }
+  self.mods++
+  
+  if self.count > 0 { self.signal(&self.hasItem) }
+  if self.count < len(self.data) { self.signal(&self.hasSpace) }
+  if self.count == 0 { self.signal(&self.isEmpty) }
+}
+
+// Appends all items in order to buf and returns the result.
+func (self *ring[T]) appendTo(buf []T) []T {
+  if self.a < self.b || self.count == 0 {
+    return append(buf, self.data[self.a:self.b]...)
+  }
+  buf = append(buf, self.data[self.a:]...)
+  return append(buf, self.data[:self.b]...)
+}
+
+// Maps idx (which must be in range) to an index into data.
+func (self *ring[T]) index(idx int) int {
+  idx += self.a
+  if idx >= len(self.data) { idx -= len(self.data) }
+  return idx
+}
+
+// Returns the item At(idx) and true, or the zero value and false if
+// idx is out of range.
+func (self *ring[T]) at(idx int) (item T, ok bool) {
+  if idx < 0 || idx >= self.count { return item, false }
+  return self.data[self.index(idx)], true
+}
+
+// Replaces the item At(idx) and returns the old item and true, or the
+// zero value and false if idx is out of range.
+func (self *ring[T]) put(idx int, item T) (old T, ok bool) {
+  if idx < 0 || idx >= self.count { return old, false }
+  idx = self.index(idx)
+  old = self.data[idx]
+  self.data[idx] = item
+  return old, true
+}
+
+// Swaps the items At(i) and At(j). Returns false if either index is out of range.
+func (self *ring[T]) swap(i, j int) bool {
+  if i < 0 || i >= self.count || j < 0 || j >= self.count { return false }
+  i = self.index(i)
+  j = self.index(j)
+  self.data[i], self.data[j] = self.data[j], self.data[i]
+  self.mods++
+  return true
+}
+
+func (self *ring[T]) reverse() {
+  for i, j := 0, self.count-1; i < j; i, j = i+1, j-1 {
+    self.swap(i, j)
+  }
+}
+
+// Stable sort. See Deque.Sort().
+func (self *ring[T]) sort(cmp func(T,T) int) {
+  self.raw(0) // move all items to data[0:count]
+  slices.SortStableFunc(self.data[0:self.count], cmp)
+  self.mods++
+}
+
+// Returns the smallest index of an item for which match() returns true, or -1.
+func (self *ring[T]) indexOf(match func(T) bool) int {
+  for i:=0; i < self.count; i++ {
+    if match(self.data[self.index(i)]) { return i }
+  }
+  return -1
+}
+
+// Removes all items for which match() returns true and returns their number.
+func (self *ring[T]) remove(match func(T) bool) int {
+  count := 0
+  for i:=self.count-1; i >= 0 ; i-- {
+    if match(self.data[self.index(i)]) { self.removeAt(i); count++ } 
+  }
+  return count
+}
+
+func (self *ring[T]) search(item T, cmp func(T,T) int) int {
+  a := 0
+  b := self.count
+  for a != b {
+    h := (a+b) >> 1
+    if cmp(self.data[self.index(h)], item) < 0 {
+      a = h+1
+    } else {
+      b = h
+    }
+  }
+  return a
+}
+
+// See Deque.Overcapacity().
+func (self *ring[T]) overcapacity(remaining uint) {
+  r := len(self.data) - self.count
+  if uint(r) != remaining {
+    new_buf := make([]T,self.count + int(remaining))
+    if self.a < self.b {
+      copy(new_buf[0:], self.data[self.a:self.b])
+    } else { // this includes the cases where a==b and self.count==0
+      copy(new_buf[copy(new_buf[0:], self.data[self.a:]):], self.data[0:self.b])
+    }
+    
+    self.data = new_buf
+    self.a = 0
+    if self.count == len(self.data) {
+      self.b = 0
+    } else {
+      self.b = self.count
+      self.signal(&self.hasSpace)
+    }
+  }
+}
+
+// See Deque.Raw().
+func (self *ring[T]) raw(index0 int) (ring []T, idx0 int) { 
+  if len(self.data) == 0 { return self.data, 0 } // Avoid division by 0
+  if index0 >= 0 {
+    index0 = index0 % len(self.data)
+    if index0 != self.a {
+      new_buf := make([]T, len(self.data))
+      if index0 > self.a {
+        // |-------A---0-----|
+        copy(new_buf[index0:], self.data[self.a:])
+        copy(new_buf[0:], self.data[self.a+len(self.data)-index0:])
+        copy(new_buf[index0-self.a:], self.data[0:self.a])
+      } else {
+        // |-------0---A-----|
+        copy(new_buf[index0:], self.data[self.a:])
+        copy(new_buf[index0+len(self.data)-self.a:], self.data[0:])
+        copy(new_buf[0:], self.data[self.a-index0:self.a])
+      }
+      self.data = new_buf
+      self.a = index0
+      self.b = self.a + self.count
+      if self.b >= len(self.data) { self.b -= len(self.data) }
+    }
+  }
+  return self.data, self.a
+}
+
+// See Deque.CheckInvariant(). Does not check the Growth function.
+func (self *ring[T]) checkInvariant() {
+  if self.count < 0 || self.count > len(self.data) { panic("invariant broken") }
+  if self.a < 0 || self.b < 0 { panic("invariant broken") }
+  if len(self.data) != 0 && (self.a >= len(self.data) || self.b >= len(self.data)) { panic("invariant broken") }
+  if len(self.data) == 0 && (self.a != 0 || self.b != 0) { panic("invariant broken") }
+  if self.a == self.b && self.count != 0 && self.count != len(self.data) { panic("invariant broken") }
+  if (self.count == 0 || self.count == len(self.data)) && self.a != self.b { panic("invariant broken") }
+  if self.a < self.b && self.count != self.b-self.a { panic("invariant broken") }
+  if self.b < self.a && self.count != (self.b + len(self.data)-self.a) { panic("invariant broken") }
+  self.waiters.checkInvariant()
+  if len(self.data) != cap(self.data) { panic("invariant broken") }
+  if self.count == 0 && len(self.isEmpty) != 0 { panic("invariant broken") }
+  if self.count != 0 && len(self.hasItem) != 0 { panic("invariant broken") }
+  if self.count < len(self.data) && len(self.hasSpace) != 0 { panic("invariant broken") }
+}
+
+//*************************** removeAt() ******************************/
+func (self *ring[T]) removeAt(idx int) (old T, ok bool) {
+  if idx < 0 || idx >= self.count { return old, false }
+  idx = self.index(idx)
+  
+  old = self.data[idx]
+  
+  if self.a < self.b { //simple case: |...A----idx----B...|
+    if idx-self.a < self.b-idx { // idx closer to A than to B
+      copy(self.data[self.a+1:],self.data[self.a:idx])
+      self.a++
+    } else { // idx closer to B than to A
+      copy(self.data[idx:],self.data[idx+1:self.b])
+      self.b--
+    }
+  } else { //harder case:   |--idx?--B.........A---idx?--|
+    da := idx - self.a
+    if da < 0 { da += len(self.data) }
+    db := self.b - idx
+    if db < 0 { db += len(self.data) }
+    if da <= db { // |--idx?----------------B.........A--idx?-|
+                  // including cases where B == A
+                  // including the special case B == A == idx
+      if idx < self.b { 
+        copy(self.data[1:], self.data[0:idx])
+        idx = len(self.data)-1
+        self.data[0] = self.data[idx]
+      }
+      copy(self.data[self.a+1:], self.data[self.a:idx])
+      self.a++
+      if self.a == len(self.data) { self.a = 0 }
+    } else { // |--idx?--B.........A-----------------idx?--|
+             // including cases where B == A
+      if idx >= self.a {
+        copy(self.data[idx:],self.data[idx+1:])
+        self.data[len(self.data)-1] = self.data[0]
+        idx = 0
+      }
+      // self.b+1 is used instead of self.b, because
+      // it is possible that self.b==0 and in that case
+      // idx+1 > self.b and the Go specs say about slices that
+      // low <= high must be satisfied.
+      // The +1 causes one additional slot to be copied but
+      // that doesn't matter because the slot it overwrites (self.b-1)
+      // is definitely unused once we do self.b--.
+      copy(self.data[idx:],self.data[idx+1:self.b+1])
+      self.b--
+      if self.b < 0 { self.b += len(self.data) }
+    }
+  }
+  
+  if self.count == len(self.data) { // we went from full to 1 available slot
+    self.signal(&self.hasSpace)
+  }
+  self.count--
+  self.mods++
+
+  if self.count == 0 { // deque is now empty
+    self.signal(&self.isEmpty)
+  }
+
+  return old, true
+}
+
+
+//*************************** insertAt() ******************************/
+// growthfunc and growthcount are the Growth and GrowthCount of the Deque.
+// Returns <0  => item discarded or index out of range
+//         >0 => item added successfully
+//          0 => wait for free space, then try again
+func (self *ring[T]) insertAt(idx int, item T, growthfunc GrowthFunc, growthcount *uint) int {
+  if idx < 0 || idx > self.count { // Note: self.count IS a valid idx for insertAt()!
+    return -1
+  }
+  
+  // If buffer is full, grow it or wait for an empty slot.
+  if self.count == len(self.data) {
+    growth := growthfunc(uint(len(self.data)), 1, *growthcount)
+    *growthcount++
+    switch growth {
+      case 0: { // no growth => block until there's space
+        return 0
+      }
+      
+      case DROP_FAR_END: { // drop far end
+        if len(self.data) == 0 { return -1 }
+        if idx+idx > self.count { // A end is far end
+          self.a++
+          if self.a == len(self.data) { self.a = 0 }
+          idx--
+        } else { // B end is far end
+          self.b--
+          if self.b < 0 { self.b += len(self.data) }
+        }
+        self.count--
+      }
+      
+      case DISCARD: { // discard the new item
+        return -1
+      }        
+      
+      default: { // grow buffer
+        self.grow(int(growth))
+        
+        if growth > 1 { // if we grew more than necessary, signal waiters for space
+          self.signal(&self.hasSpace)
+        }
+      }
+    }
+  }
+  
+  // At this point we have an empty slot. Let's insert!
+  
+  idx += self.a
+  if idx >= len(self.data) { idx -= len(self.data) }
+  
+  if self.a <= self.b { //simple case: |...A----idx----B...|
+                        //including the case A == B == idx (empty Deque)
+    if idx-self.a < self.b-idx { // idx closer to A than to B
+      a := self.a
+      self.a--
+      if self.a < 0 { self.a += len(self.data) }
+      // copy 1st element separately because of possible wrap-around
+      self.data[self.a] = self.data[a]
+      
+      // We use idx+1 because it is possible that idx==a and
+      // the Go specs say that low <= high must be satisfied.
+      // This may cause data[idx] to be copied to data[idx-1]
+      // but this unnecessary copy doesn't hurt.
+      copy(self.data[a:], self.data[a+1:idx+1])
+      idx-- // insert before the item
+      if idx < 0 { idx += len(self.data) }
+    } else { // idx closer to B than to A 
+             //   or 
+             // A == B == idx (empty Deque)
+      // because self.data[self.b] is an empty slot, the following
+      // copy cannot wrap around unlike the "closer to A" case
+      copy(self.data[idx+1:len(self.data)], self.data[idx:self.b])
+      self.b++
+      if self.b == len(self.data) { self.b = 0 }
+    }
+  } else { //harder case:   |--idx?--B.........A---idx?--|
+           //Note that A != B because the Deque cannot be full and 
+           //the case of an empty Deque is handled above.
+    da := idx - self.a
+    if da < 0 { da += len(self.data) }
+    db := self.b - idx
+    if db < 0 { db += len(self.data) }
+    if da < db { // |--idx?----------------B.........A--idx?-|
+      idx-- // insert before the item
+      if idx < 0 { idx += len(self.data) }
+      self.a-- // cannot wrap around
+      if idx >= self.a { // |----------------------B.........A--idx--|
+        copy(self.data[self.a:], self.data[self.a+1:idx+1])
+      } else 
+      { // |--idx-----------------B.........A-------|
+        copy(self.data[self.a:], self.data[self.a+1:len(self.data)])
+          // copy 1st element separately because of wrap-around
+        self.data[len(self.data)-1] = self.data[0]
+        copy(self.data[0:], self.data[1:idx+1])
+      } 
+    } else { // |--idx?--B.........A-----------------idx?--|
+      if idx <= self.b { // |--idx--B.........A-----------------------|
+        copy(self.data[idx+1:], self.data[idx:self.b])
+        self.b++ // cannot wrap around
+      } else
+      { // |------B.........A-----------------idx--|
+        copy(self.data[1:], self.data[0:self.b])
+        self.data[0] = self.data[len(self.data)-1]
+        copy(self.data[idx+1:len(self.data)], self.data[idx:len(self.data)-1])
+        self.b++ // cannot wrap around
+      }
+    }
+  }
+
+  self.data[idx] = item
+
+  if self.count == 0 { // we inserted into an empty deque => signal waiters for item
+    self.signal(&self.hasItem)
+  }
+  self.count++
+  self.mods++
+  
+  return 1
+}
+
+
+// Inserts item at the front, growing the buffer if necessary regardless
+// of the Growth function. Used to return an item that has been removed
+// and must not be lost.
+func (self *ring[T]) putBack(item T) {
+  var growthcount uint
+  self.insertAt(0, item, Double, &growthcount)
+}
+
+// Increases the capacity by growth > 0 and moves the items to the start
+// of the new buffer.
+func (self *ring[T]) grow(growth int) {
+  new_buf := make([]T, len(self.data) + growth)
+  self.appendTo(new_buf[0:0])
+  self.a = 0
+  self.b = self.count // cannot wrap around because growth > 0
+  self.data = new_buf
+}
+
+//*************************** pushMany() ******************************/
+// Appends items at the Push() end, calling growthfunc if they don't fit
+// (see insertAt()). Returns the number of items consumed from the front of
+// items (i.e. added or discarded) and the number of items added. As with
+// Push(), items that are dropped due to DROP_FAR_END count as added.
+// If consumed < len(items), the caller must wait for free space and then
+// call pushMany() again with the remaining items.
+func (self *ring[T]) pushMany(items []T, growthfunc GrowthFunc, growthcount *uint) (consumed int, added int) {
+  free := len(self.data) - self.count
+  fits := len(items)
+  if fits > free {
+    growth := growthfunc(uint(len(self.data)), uint(len(items) - free), *growthcount)
+    *growthcount++
+    switch growth {
+      case 0: // no growth => add what fits, then block until there's space
+        fits = free
+      
+      case DROP_FAR_END: { // drop items from the At(0) end
+        if len(self.data) == 0 { return len(items), 0 }
+        // Only the last len(data) items of the batch can remain.
+        if len(items) > len(self.data) { 
+          consumed = len(items) - len(self.data)
+          added = consumed
+          items = items[consumed:]
+          fits = len(items)
+        }
+        drop := fits - free
+        if drop > self.count { drop = self.count }
+        self.removeFront(drop, nil)
+      }
+      
+      case DISCARD: { // discard the items that don't fit
+        consumed = len(items) - free
+        fits = free
+      }
+      
+      default: { // grow buffer
+        self.grow(int(growth))
+        if fits > len(self.data) - self.count { 
+          fits = len(self.data) - self.count 
+        } else if fits < len(self.data) - self.count {
+          // if we grew more than necessary, signal waiters for space
+          self.signal(&self.hasSpace)
+        }
+      }
+    }
+  }
+  
+  if fits == 0 { return consumed, added }
+  
+  was_empty := self.count == 0
+  for _, item := range items[0:fits] {
+    self.data[self.b] = item
+    self.b++
+    if self.b == len(self.data) { self.b = 0 }
+  }
+  self.count += fits
+  self.mods++
+  if was_empty { self.signal(&self.hasItem) }
+  
+  return consumed + fits, added + fits
+}
+
+// Removes up to n items from the At(0) end and appends them to buf.
+// Returns the extended buf.
+func (self *ring[T]) removeFront(n int, buf []T) []T {
+  if n > self.count { n = self.count }
+  if n <= 0 { return buf }
+  was_full := self.count == len(self.data)
+  for i := 0; i < n; i++ {
+    buf = append(buf, self.data[self.a])
+    var zero T
+    self.data[self.a] = zero // don't keep a reference for the garbage collector
+    self.a++
+    if self.a == len(self.data) { self.a = 0 }
+  }
+  self.count -= n
+  self.mods++
+  if was_full { self.signal(&self.hasSpace) }
+  if self.count == 0 { self.signal(&self.isEmpty) }
+  return buf
+}
@@ -0,0 +1,186 @@
+/* Copyright (C) 2012 Matthias S. Benkmann
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this file (originally named select.go) and associated documentation files 
+ * (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is furnished
+ * to do so, subject to the following conditions:
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ * 
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE. 
+ */
+
+package deque
+
+import (
+          "context"
+          "reflect"
+          "sync"
+          "time"
+       )
+
+/*********************************************************************************
+
+                   WAITING ON MULTIPLE DEQUES
+
+*********************************************************************************/
+
+// Blocks until at least one of the deques has an item or the timeout
+// has elapsed (0 means wait as long as necessary). Then removes the first item
+// (i.e. At(0)) from the first Deque in the list that has an item and returns that
+// item and the Deque it came from. So if the deques are passed in order
+// of decreasing priority, NextAny() will always serve the most important
+// non-empty Deque.
+// On timeout NextAny() returns nil, nil. If no deques are passed, it
+// returns nil, nil immediately.
+//
+// The deques are locked one at a time, never simultaneously, so NextAny()
+// cannot deadlock with other operations. The same Deque may be passed
+// multiple times.
+func NextAny(timeout time.Duration, deques... *Deque) (item interface{}, source *Deque) {
+  if len(deques) == 0 { return nil, nil }
+  candidates := make([]candidate[interface{}], len(deques))
+  for i, d := range deques {
+    d := d
+    candidates[i] = candidate[interface{}]{&d.Mutex, &d.ring, func(){ d.init() }}
+  }
+  item, i := nextAny(timeout, candidates)
+  if i < 0 { return nil, nil }
+  return item, deques[i]
+}
+
+// Like NextAny() for Of[T]. On timeout (or if no deques are passed) it
+// returns the zero value and nil.
+func NextAnyOf[T any](timeout time.Duration, deques... *Of[T]) (item T, source *Of[T]) {
+  if len(deques) == 0 { return item, nil }
+  candidates := make([]candidate[T], len(deques))
+  for i, d := range deques {
+    d := d
+    candidates[i] = candidate[T]{&d.Mutex, &d.ring, func(){ d.init() }}
+  }
+  item, i := nextAny(timeout, candidates)
+  if i < 0 { return item, nil }
+  return item, deques[i]
+}
+
+// Returns a channel from which the items of the Deque can be received in
+// the order Next() would return them. This allows using a Deque in a
+// native select statement. A goroutine removes the items from the
+// Deque one at a time with NextCtx() and holds each item until it has
+// been received. So while nobody receives from the channel, one item has
+// already been taken from the Deque: It is not counted by Count(), not
+// visible to Peek() etc. and not available to other consumers.
+// When ctx is done, the goroutine stops and closes the channel. An item
+// it holds at that time is put back at the front of the Deque, so that
+// no item is lost. This ignores the Growth function, i.e. the Deque grows
+// if necessary instead of blocking or dropping an item.
+func (self *Deque) Chan(ctx context.Context) <-chan interface{} {
+  c := make(chan interface{})
+  go func() {
+    defer close(c)
+    for {
+      item, err := self.NextCtx(ctx)
+      if err != nil { return }
+      select {
+        case c <- item:
+        case <-ctx.Done():
+          self.Mutex.Lock()
+          self.putBack(item)
+          self.Mutex.Unlock()
+          return
+      }
+    }
+  }()
+  return c
+}
+
+// See Deque.Chan().
+func (self *Of[T]) Chan(ctx context.Context) <-chan T {
+  c := make(chan T)
+  go func() {
+    defer close(c)
+    for {
+      item, err := self.NextCtx(ctx)
+      if err != nil { return }
+      select {
+        case c <- item:
+        case <-ctx.Done():
+          self.Mutex.Lock()
+          self.putBack(item)
+          self.Mutex.Unlock()
+          return
+      }
+    }
+  }()
+  return c
+}
+
+// A Deque or Of[T] that nextAny() takes items from.
+type candidate[T any] struct {
+  mutex *sync.Mutex
+  ring *ring[T]
+  // Initializes the Deque if it has not been used yet. Called with mutex locked.
+  init func()
+}
+
+// Implements NextAny(). Returns the item and the index of its candidate, or
+// -1 on timeout.
+func nextAny[T any](timeout time.Duration, candidates []candidate[T]) (item T, idx int) {
+  var deadline <-chan time.Time
+  if timeout > 0 {
+    timer := time.NewTimer(timeout)
+    defer timer.Stop()
+    deadline = timer.C
+  }
+  
+  waiters := make([]chan bool, len(candidates))
+  // Removes all waiters registered by the current round.
+  unregister := func() {
+    for i, c := range waiters {
+      if c == nil { continue }
+      src := candidates[i]
+      src.mutex.Lock()
+      src.ring.unregister(&src.ring.hasItem, c)
+      src.mutex.Unlock()
+      waiters[i] = nil
+    }
+  }
+  
+  for {
+    for i, src := range candidates {
+      src.mutex.Lock()
+      if src.ring.data == nil { src.init() }
+      if src.ring.count > 0 {
+        item, _ = src.ring.removeAt(0)
+        src.mutex.Unlock()
+        unregister()
+        return item, i
+      }
+      // Register a waiter while still holding the lock, so that an item
+      // added after the check can not be missed.
+      waiters[i] = make(chan bool, 2)
+      src.ring.hasItem = append(src.ring.hasItem, waiters[i])
+      src.mutex.Unlock()
+    }
+    
+    cases := make([]reflect.SelectCase, 0, len(waiters)+1)
+    for _, c := range waiters {
+      cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c)})
+    }
+    if deadline != nil {
+      cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(deadline)})
+    }
+    chosen, _, _ := reflect.Select(cases)
+    unregister()
+    if chosen == len(waiters) { return item, -1 } // timeout
+  }
+}
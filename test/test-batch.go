@@ -0,0 +1,143 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-batch.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt"
+         "time"
+         "../deque"
+       )
+
+func main() {
+  d := deque.New(4)
+  d.Push(0)
+  d.Next() // move the start away from index 0 to test wrap-around
+  fmt.Printf("PushMany() with growth ... ")
+  if d.PushMany(1,2,3,4,5,6) == 6 && d.String() == "Deque[1 2 3 4 5 6]" && d.GrowthCount == 1 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", d)
+    os.Exit(1)
+  }
+  d.CheckInvariant()
+  fmt.Printf("NextN() ... ")
+  if fmt.Sprint(d.NextN(4, 0)) == "[1 2 3 4]" && d.Count() == 2 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("NextN() returns what is there ... ")
+  if fmt.Sprint(d.NextN(10, 0)) == "[5 6]" && d.IsEmpty() { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  start := time.Now()
+  fmt.Printf("NextN() timeout ... ")
+  if d.NextN(3, 20*time.Millisecond) == nil && time.Since(start) >= 20*time.Millisecond { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("NextN(0) ... ")
+  if d.Push(1) && d.NextN(0, 0) == nil && d.Count() == 1 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  go d.PushMany(2,3)
+  d.Next()
+  fmt.Printf("NextN() waits for item ... ")
+  if fmt.Sprint(d.NextN(5, 0)) == "[2 3]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  d.CheckInvariant()
+
+  d.PushMany("a","b","c")
+  buf := d.DrainTo([]interface{}{"x"})
+  fmt.Printf("DrainTo() ... ")
+  if fmt.Sprint(buf) == "[x a b c]" && d.IsEmpty() && d.Capacity() == 8 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", buf, d.Capacity())
+    os.Exit(1)
+  }
+  fmt.Printf("DrainTo() on empty Deque ... ")
+  if len(d.DrainTo(nil)) == 0 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  d.CheckInvariant()
+
+  mru := deque.NewOf[int](4, deque.DropFarEndIfOverflow)
+  mru.PushMany(1,2,3)
+  fmt.Printf("PushMany() with DropFarEndIfOverflow ... ")
+  if mru.PushMany(4,5,6) == 3 && mru.String() == "Deque[3 4 5 6]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", mru)
+    os.Exit(1)
+  }
+  fmt.Printf("PushMany() larger than capacity ... ")
+  if mru.PushMany(7,8,9,10,11,12) == 6 && mru.String() == "Deque[9 10 11 12]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", mru)
+    os.Exit(1)
+  }
+  mru.CheckInvariant()
+
+  discard := deque.NewOf[int](4, deque.DropItemIfOverflow)
+  discard.Push(1)
+  fmt.Printf("PushMany() with DropItemIfOverflow ... ")
+  if discard.PushMany(2,3,4,5,6) == 3 && discard.String() == "Deque[1 2 3 4]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", discard)
+    os.Exit(1)
+  }
+  discard.CheckInvariant()
+
+  func() {
+    defer func() { recover() }()
+    strict := deque.NewOf[int](2, deque.PanicIfOverflow)
+    defer func() {
+      fmt.Printf("PushMany() with PanicIfOverflow leaves Deque unchanged ... ")
+      if strict.Count() == 0 { fmt.Println("OK") } else {
+        fmt.Println("FAIL", strict)
+        os.Exit(1)
+      }
+    }()
+    strict.PushMany(1,2,3)
+  }()
+
+  exact := deque.NewOf[int](2, deque.GrowBy(1))
+  fmt.Printf("PushMany() requests the missing capacity ... ")
+  if exact.PushMany(1,2,3,4,5) == 5 && exact.Capacity() == 5 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", exact.Capacity())
+    os.Exit(1)
+  }
+
+  // BlockIfFull: the batch is split between consumer rounds
+  queue := deque.NewOf[int](3, deque.BlockIfFull)
+  done := make(chan int)
+  go func() {
+    items := make([]int, 100)
+    for i := range items { items[i] = i+1 }
+    done <- queue.PushMany(items...)
+  }()
+  sum := 0
+  got := 0
+  for got < 100 {
+    batch := queue.NextN(2, 0)
+    if len(batch) == 0 || len(batch) > 2 { break }
+    for _, x := range batch { sum += x }
+    got += len(batch)
+  }
+  fmt.Printf("PushMany() with BlockIfFull ... ")
+  if <-done == 100 && sum == 5050 && queue.Capacity() == 3 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", sum)
+    os.Exit(1)
+  }
+  queue.CheckInvariant()
+
+  var ints deque.Of[int]
+  ints.PushMany(3,4)
+  fmt.Printf("Of[T].DrainTo() ... ")
+  if fmt.Sprint(ints.DrainTo(nil)) == "[3 4]" && ints.IsEmpty() { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+}
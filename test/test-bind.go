@@ -0,0 +1,70 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-bind.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "strings"
+         "../argv"
+       )
+
+type config struct {
+  Verbose int      `short:"v" long:"verbose" checker:"none" help:"Increase verbosity."`
+  Port int         `short:"p" long:"port" arg:"<num>" help:"Port to listen on."`
+  Name string      `short:"n" long:"name" help:"Server name."`
+  Include []string `short:"I" long:"include" arg:"<dir>" help:"Add include directory."`
+  Ratio float64    `long:"ratio" help:"Compression ratio."`
+  Daemon bool      `short:"d" long:"daemon" help:"Run in background."`
+  ignored string
+}
+
+type flags struct {
+  Flag bool `long:"flag" checker:"bool"`
+}
+
+func main() {
+  cfg := config{Port:80, Name:"default"}
+  args := strings.Fields("-vv -p 8080 file1 -I/usr/include --include=/opt/include --ratio=0.5 --verbose -d file2")
+  nonoptions, err := argv.ParseStruct(args, &cfg, "")
+  if err != nil {
+    fmt.Println(err)
+    os.Exit(1)
+  }
+  
+  result := fmt.Sprintf("%+v %v", cfg, nonoptions)
+  expected := "{Verbose:3 Port:8080 Name:default Include:[/usr/include /opt/include] Ratio:0.5 Daemon:true ignored:} [file1 file2]"
+  fmt.Printf("%v ... ", result)
+  if result == expected { fmt.Println("OK") } else {
+    fmt.Printf("FAIL (expected %v)\n", expected)
+    os.Exit(1)
+  }
+  
+  _, err = argv.ParseStruct([]string{"--port=x"}, &cfg, "")
+  fmt.Printf("%v ... ", err)
+  if err == nil { 
+    fmt.Println("FAIL") 
+    os.Exit(1)
+  } else { fmt.Println("OK") }
+  
+  fl := flags{Flag:true}
+  _, err = argv.ParseStruct([]string{"--flag=false"}, &fl, "")
+  fmt.Printf("--flag=false with checker:\"bool\" ... ")
+  if err == nil && !fl.Flag { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, fl)
+    os.Exit(1)
+  }
+  _, err = argv.ParseStruct([]string{"--flag=false", "--flag=yes"}, &fl, "")
+  fmt.Printf("--flag=false --flag=yes with checker:\"bool\" ... ")
+  if err == nil && fl.Flag { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, fl)
+    os.Exit(1)
+  }
+  
+  usage, _ := argv.StructUsage(&cfg)
+  fmt.Println(usage)
+}
@@ -0,0 +1,67 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-checkers.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "../argv"
+       )
+
+type test struct {
+  check argv.ArgChecker
+  arg string
+  expected string
+}
+
+var tests = []test{
+  {argv.ArgFloat, "1.5", "1.5"},
+  {argv.ArgFloat, "x", "ERROR"},
+  {argv.ArgDuration, "1h30m", "1h30m0s"},
+  {argv.ArgDuration, "10", "ERROR"},
+  {argv.ArgBool, "yes", "true"},
+  {argv.ArgBool, "0", "false"},
+  {argv.ArgBool, "maybe", "ERROR"},
+  {argv.ArgEnum("red","green"), "green", "green"},
+  {argv.ArgEnum("red","green"), "blue", "ERROR"},
+  {argv.ArgIntRange(1,10), "10", "10"},
+  {argv.ArgIntRange(1,10), "11", "ERROR"},
+  {argv.ArgSize, "512", "512"},
+  {argv.ArgSize, "64k", "65536"},
+  {argv.ArgSize, "1.5G", "1610612736"},
+  {argv.ArgSize, "2MiB", "2097152"},
+  {argv.ArgSize, "1.5", "ERROR"},
+  {argv.ArgSize, "-1", "ERROR"},
+  {argv.ArgSize, "k", "ERROR"},
+  {argv.ArgExistingFile, ".", "."},
+  {argv.ArgExistingFile, "/does/not/exist", "ERROR"},
+  {argv.ArgRegexp, "^a+$", "^a+$"},
+  {argv.ArgRegexp, "(", "ERROR"},
+  {argv.ArgKeyValue, "a=b=c", "{a b=c}"},
+  {argv.ArgKeyValue, "=b", "ERROR"},
+}
+
+func main() {
+  for _, t := range tests {
+    option := &argv.Option{Name:"--test", HasArg:true, Arg:t.arg}
+    err := t.check(option)
+    result := "ERROR"
+    if err == nil {
+      if fi, ok := option.Value.(os.FileInfo); ok {
+        result = fi.Name()
+      } else {
+        result = fmt.Sprintf("%v", option.Value)
+      }
+    }
+    fmt.Printf("%-15v => %-12v ", t.arg, result)
+    if err != nil { fmt.Printf("(%v) ", err) }
+    if result == t.expected { fmt.Println("... OK") } else {
+      fmt.Printf("... FAIL (expected %v)\n", t.expected)
+      os.Exit(1)
+    }
+  }
+}
@@ -0,0 +1,79 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-commands.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "strings"
+         "../argv"
+       )
+
+const (
+  UNKNOWN = iota
+  VERBOSE
+  FORCE
+)
+
+var add = &argv.Command{Name:"add", Help:"Add a remote.", Usage:argv.Usage{
+{ UNKNOWN, 1, "", "",     argv.ArgUnknown, "USAGE: tool remote add [options] <name>" },
+{ FORCE,   1, "f","force",argv.ArgNone,    "  -f, \t--force  \tOverwrite existing remote." },
+}}
+
+var remove = &argv.Command{Name:"remove", Help:"Remove a remote.", Usage:argv.Usage{
+{ UNKNOWN, 1, "", "",     argv.ArgUnknown, "USAGE: tool remote remove <name>" },
+}}
+
+var remote = &argv.Command{Name:"remote", Help:"Manage remotes.", Commands:[]*argv.Command{add,remove}, Usage:argv.Usage{
+{ UNKNOWN, 1, "", "",     argv.ArgUnknown, "USAGE: tool remote <command> ..." },
+}}
+
+var tool = &argv.Command{Name:"tool", Commands:[]*argv.Command{remote}, Usage:argv.Usage{
+{ UNKNOWN, 1, "", "",       argv.ArgUnknown, "USAGE: tool [options] <command> ..." },
+{ VERBOSE, 1, "v","verbose",argv.ArgNone,    "  -v, \t--verbose  \tIncrease verbosity." },
+}}
+
+type test struct {
+  args string
+  flags string
+  expected string
+}
+
+var tests = []test{
+  {"-v remote add --force x", "", "tool:1 remote:0 add:1 [x]"},
+  {"-v -v remote remove x y", "", "tool:2 remote:0 remove:0 [x y]"},
+  {"remote -v", "", "ERROR"},
+  {"foo remote add", "", "tool:0 [foo remote add]"},
+  {"foo -v", "gnu", "tool:1 [foo]"},
+  {"-- remote add", "", "tool:0 [remote add]"},
+  {"remote add x --force", "gnu", "tool:0 remote:0 add:1 [x]"},
+  {"remote add x --force", "", "tool:0 remote:0 add:0 [x --force]"},
+}
+
+func main() {
+  for _, t := range tests {
+    path, options, nonoptions, err, _ := argv.ParseCommands(strings.Fields(t.args), tool, t.flags)
+    result := "ERROR"
+    if err == nil {
+      result = ""
+      for i, cmd := range path {
+        count := 0
+        for _, opt := range options[i] { count += opt.Count() }
+        result += fmt.Sprintf("%v:%v ", cmd.Name, count)
+      }
+      result += fmt.Sprintf("%v", nonoptions)
+    }
+    fmt.Printf("%-25v %-5v => %v ... ", t.args, t.flags, result)
+    if result == t.expected { fmt.Println("OK") } else {
+      fmt.Printf("FAIL (expected %v)\n", t.expected)
+      os.Exit(1)
+    }
+  }
+  
+  fmt.Println(tool)
+  fmt.Println(remote)
+}
@@ -0,0 +1,45 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-completion.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "../argv"
+       )
+
+func ArgColor(option *argv.Option) error {
+  if s, ok := option.Value.(*argv.Suggestions); ok {
+    s.Words = []string{"red", "green", "blue"}
+    return argv.ARG_OK
+  }
+  if option.HasArg && (option.Arg == "red" || option.Arg == "green" || option.Arg == "blue") {
+    return argv.ARG_OK
+  }
+  return fmt.Errorf("Option '%v' requires a color as argument", option)
+}
+
+var usage = argv.Usage{
+{ 0, 1, "", "",          argv.ArgUnknown, "USAGE: test-completion [options]" },
+{ 1, 1, "c","color",     ArgColor,        "  -c <color>, \t--color=<color>  \tChoose [red], green or blue." },
+{ 2, 1, "vV","verbose",  argv.ArgNone,    "  -v, \t--verbose  \tIncrease verbosity." },
+{ 3, 1, "o","optional",  argv.ArgOptional,"  -o[<arg>], \t--optional[=<arg>]  \tIt's optional." },
+{ 4, 1, "f","file",      argv.ArgRequired,"  -f <file>, \t--file=<file>  \tRead\vfile." },
+{ 5, 1, "", "very",      argv.ArgNone,    "  \t--very  \tVery." },
+}
+
+// Usage: test-completion bash|zsh|fish
+func main() {
+  shell := "bash"
+  if len(os.Args) > 1 { shell = os.Args[1] }
+  script, err := usage.CompletionScript(shell, "test-completion", "-perl --abb")
+  if err != nil {
+    fmt.Fprintln(os.Stderr, err)
+    os.Exit(1)
+  }
+  fmt.Print(script)
+}
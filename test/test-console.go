@@ -0,0 +1,143 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-console.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "io"
+         "os"
+         "fmt" 
+         "net"
+         "time"
+         "bytes"
+         "errors"
+         "strings"
+         "../argv"
+         "../util"
+       )
+
+const (
+  UNKNOWN = iota
+  TIMEOUT
+  VERBOSE
+)
+
+var setUsage = argv.Usage{
+{ UNKNOWN, 0, "", "",        argv.ArgUnknown, "USAGE: set [options] <name>" },
+{ TIMEOUT, 0, "t","timeout", argv.ArgInt,     "  -t <n>, \t--timeout=<n>  \tTimeout in seconds." },
+{ VERBOSE, 0, "v","verbose", argv.ArgNone,    "  -v, \t--verbose  \tBe verbose." },
+}
+
+var settings = map[string]string{}
+
+func set(out io.Writer, options []*argv.Option, nonoptions []string) error {
+  if len(nonoptions) != 1 { return errors.New("set: expected exactly 1 name") }
+  timeout := "default"
+  if options[TIMEOUT] != nil { timeout = options[TIMEOUT].Last().Arg }
+  settings[nonoptions[0]] = timeout
+  fmt.Fprintf(out, "%v=%v\n", nonoptions[0], timeout)
+  return nil
+}
+
+func quit(out io.Writer, options []*argv.Option, nonoptions []string) error {
+  fmt.Fprintf(out, "bye\n")
+  return argv.ConsoleQuit
+}
+
+var console = &argv.Console{
+  Commands: []*argv.ConsoleCommand{
+    {Command: argv.Command{Name: "set", Usage: setUsage, Flags: "gnu", Help: "Set a timeout."}, Handler: set},
+    {Command: argv.Command{Name: "quit", Help: "Leave the console."}, Handler: quit},
+  },
+  Prompt: "> ",
+  Expand: func(name string) string { return map[string]string{"NAME": "foo bar"}[name] },
+  Formatter: &argv.Formatter{Columns: 80, LastColumnMinPercent: 50, LastColumnOwnLineMaxPercent: 75},
+}
+
+var input = `set --timeout=5 foo
+  # comment
+
+set -t 10 "$NAME"
+set -t ten x
+sett x
+set 'unterminated
+help
+help set
+set a b
+quit
+set --timeout=1 never
+`
+
+var expected = `> foo=5
+> > > foo bar=10
+> Option '-t' requires an integer as argument
+USAGE: set [options] <name>
+  -t <n>, --timeout=<n>  Timeout in seconds.
+  -v,     --verbose      Be verbose.
+> Unknown command 'sett'. Did you mean set?
+Commands:
+  set               Set a timeout.
+  quit              Leave the console.
+  help [<command>]  List commands or show help for <command>.
+> Line 1: Unterminated ' quote
+> Commands:
+  set               Set a timeout.
+  quit              Leave the console.
+  help [<command>]  List commands or show help for <command>.
+> USAGE: set [options] <name>
+  -t <n>, --timeout=<n>  Timeout in seconds.
+  -v,     --verbose      Be verbose.
+> set: expected exactly 1 name
+> bye
+`
+
+func main() {
+  var out bytes.Buffer
+  err := console.Run(strings.NewReader(input), &out)
+  if out.String() != expected { fmt.Print(out.String()) }
+  fmt.Printf("Run() output ... ")
+  if err == nil && out.String() == expected { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("commands executed ... ")
+  if len(settings) == 2 && settings["foo"] == "5" && settings["foo bar"] == "10" { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  
+  out.Reset()
+  err = console.Run(strings.NewReader("set x\r\nset y"), &out)
+  fmt.Printf("CRLF and final line without newline ... ")
+  if err == nil && out.String() == "> x=default\n> y=default\n" { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  
+  // Run the console on a network connection, then continue reading with util.ReadLn().
+  client, server := net.Pipe()
+  go func() {
+    client.Write([]byte("set -t 3 net\nquit\nafter console\n"))
+  }()
+  go func() {
+    buf := make([]byte, 1000)
+    for { if _, err := client.Read(buf); err != nil { return } }
+  }()
+  err = console.Run(server, server)
+  fmt.Printf("Run() on a net.Conn ... ")
+  if err == nil && settings["net"] == "3" { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  line, err := util.ReadLn(server, 5 * time.Second)
+  fmt.Printf("data after quit remains available to util.ReadLn() ... ")
+  if err == nil && line == "after console" { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  server.Close()
+  client.Close()
+}
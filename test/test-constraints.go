@@ -0,0 +1,76 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-constraints.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "strings"
+         "../argv"
+       )
+
+const (
+  UNKNOWN = iota
+  INPUT
+  QUIET
+  VERBOSE
+  KEY
+  CERT
+)
+
+var usage = argv.Usage{
+{ UNKNOWN, 1, "", "",       argv.ArgUnknown, "USAGE: test-constraints [options]" },
+{ INPUT,   1, "i","input",  argv.ArgRequired,"  -i <file>, \t--input=<file>  \tInput file (mandatory)." },
+{ QUIET,   1, "q","quiet",  argv.ArgNone,    "  -q, \t--quiet  \tNo output." },
+{ VERBOSE, 1, "v","verbose",argv.ArgNone,    "  -v, \t--verbose  \tMore output." },
+{ KEY,     1, "", "key",    argv.ArgRequired,"  \t--key=<file>  \tPrivate key." },
+{ CERT,    1, "", "cert",   argv.ArgRequired,"  \t--cert=<file>  \tCertificate." },
+}
+
+var constraints = []argv.Constraint{
+  argv.Required(INPUT),
+  argv.Exclusive(QUIET, VERBOSE),
+  argv.Requires(KEY, CERT),
+}
+
+type test struct {
+  args string
+  expected string
+}
+
+var tests = []test{
+  {"-i foo -v --key=k --cert=c", "OK"},
+  {"-v", "Option '--input' is required"},
+  {"-i foo -q --verbose", "Options '-q', '--verbose' are mutually exclusive"},
+  {"-i foo --key=k", "Option '--key' requires '--cert'"},
+  {"-q -v --key k", "Option '--input' is required|Options '-q', '-v' are mutually exclusive|Option '--key' requires '--cert'"},
+}
+
+func main() {
+  for _, t := range tests {
+    options, _, err, _ := argv.Parse(strings.Fields(t.args), usage, "")
+    if err == nil { err = argv.CheckConstraints(usage, options, constraints...) }
+    result := "OK"
+    if err != nil { result = strings.Replace(err.Error(), "\n", "|", -1) }
+    fmt.Printf("%-28v => %v ... ", t.args, result)
+    if result == t.expected { fmt.Println("OK") } else {
+      fmt.Printf("FAIL (expected %v)\n", t.expected)
+      os.Exit(1)
+    }
+  }
+  
+  options, _, _, _ := argv.Parse([]string{"-i", "foo", "-q"}, usage, "")
+  options[VERBOSE] = &argv.Option{Name: "--verbose", Source: "$VERBOSE"}
+  err := argv.CheckConstraints(usage, options, constraints...)
+  expected := "Options '-q', '--verbose (from $VERBOSE)' are mutually exclusive"
+  fmt.Printf("option from environment => %v ... ", err)
+  if err == nil || err.Error() != expected {
+    fmt.Printf("FAIL (expected %v)\n", expected)
+    os.Exit(1)
+  }
+  fmt.Println("OK")
+}
@@ -0,0 +1,118 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-dequectx.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt"
+         "time"
+         "context"
+         "../deque"
+       )
+
+func main() {
+  var d deque.Deque
+
+  ctx, cancel := context.WithCancel(context.Background())
+  go cancel()
+  item, err := d.NextCtx(ctx)
+  fmt.Printf("NextCtx() cancelled ... ")
+  if item == nil && err == context.Canceled { fmt.Println("OK") } else {
+    fmt.Println("FAIL", item, err)
+    os.Exit(1)
+  }
+  d.CheckInvariant()
+
+  item, err = d.PopCtx(ctx)
+  fmt.Printf("PopCtx() with done context ... ")
+  if item == nil && err == context.Canceled { fmt.Println("OK") } else {
+    fmt.Println("FAIL", item, err)
+    os.Exit(1)
+  }
+
+  d.Push(1)
+  d.Push(2)
+  item, err = d.NextCtx(context.Background())
+  fmt.Printf("NextCtx() with item ... ")
+  if item == 1 && err == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL", item, err)
+    os.Exit(1)
+  }
+  item, err = d.PopCtx(context.Background())
+  fmt.Printf("PopCtx() with item ... ")
+  if item == 2 && err == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL", item, err)
+    os.Exit(1)
+  }
+
+  go d.Push(3)
+  fmt.Printf("WaitForItemCtx() ... ")
+  if d.WaitForItemCtx(context.Background()) == nil && d.Count() == 1 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  tctx, tcancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+  defer tcancel()
+  fmt.Printf("WaitForEmptyCtx() deadline ... ")
+  if d.WaitForEmptyCtx(tctx) == context.DeadlineExceeded { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  d.CheckInvariant()
+
+  full := deque.New(1, deque.BlockIfFull)
+  full.Push("a")
+  ctx, cancel = context.WithCancel(context.Background())
+  go cancel()
+  ok, err := full.PushCtx(ctx, "b")
+  fmt.Printf("PushCtx() cancelled ... ")
+  if !ok && err == context.Canceled && full.String() == "Deque[a]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ok, err, full)
+    os.Exit(1)
+  }
+  full.CheckInvariant()
+
+  go full.Next()
+  ok, err = full.PushCtx(context.Background(), "c")
+  fmt.Printf("PushCtx() after space ... ")
+  if ok && err == nil && full.String() == "Deque[c]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ok, err, full)
+    os.Exit(1)
+  }
+  fmt.Printf("WaitForSpaceCtx() with done context ... ")
+  if full.WaitForSpaceCtx(ctx) == context.Canceled { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  discard := deque.New(0, deque.DropItemIfOverflow)
+  ok, err = discard.PushCtx(context.Background(), 1)
+  fmt.Printf("PushCtx() discarded ... ")
+  if !ok && err == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ok, err)
+    os.Exit(1)
+  }
+
+  ints := deque.NewOf[int]()
+  ctx, cancel = context.WithCancel(context.Background())
+  results := make(chan error)
+  for i := 0; i < 3; i++ {
+    go func() { _, err := ints.NextCtx(ctx); results <- err }()
+  }
+  ints.Push(42)
+  n := 0
+  if <-results == nil { n++ }
+  cancel()
+  for i := 0; i < 2; i++ { if <-results == context.Canceled { n++ } }
+  fmt.Printf("Of[T].NextCtx() consumers torn down ... ")
+  if n == 3 && ints.Count() == 0 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", n)
+    os.Exit(1)
+  }
+  ints.CheckInvariant()
+}
@@ -0,0 +1,108 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-fallback.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "strings"
+         "../argv"
+       )
+
+const (
+  UNKNOWN = iota
+  PORT
+  HOST
+  DAEMON
+)
+
+var usage = argv.Usage{
+{ UNKNOWN, 1, "", "",      argv.ArgUnknown, "USAGE: daemon [options]" },
+{ PORT,    1, "p","port",  argv.WithMeta(argv.ArgInt, argv.OptionMeta{Env:"FOO_PORT", Key:"port"}),
+                                            "  -p <num>, \t--port=<num>  \tPort to listen on." },
+{ HOST,    1, "", "host",  argv.WithMeta(argv.ArgRequired, argv.OptionMeta{Env:"FOO_HOST", Key:"host"}),
+                                            "  \t--host=<name>  \tHost to bind to." },
+{ DAEMON,  1, "d","daemon",argv.WithMeta(argv.ArgNone, argv.OptionMeta{Env:"FOO_DAEMON", Key:"daemon"}),
+                                            "  -d, \t--daemon  \tRun in background." },
+}
+
+var env = []string{"FOO_PORT", "FOO_HOST", "FOO_DAEMON"}
+
+var configfile = `
+# test configuration
+port = 1234
+host: example.com
+daemon = false
+`
+
+type test struct {
+  args string
+  env map[string]string
+  expected string
+}
+
+var tests = []test{
+  {"", nil, "1234 example.com false"},
+  {"-p 80", map[string]string{"FOO_PORT":"8080"}, "80 example.com false"},
+  {"", map[string]string{"FOO_PORT":"8080", "FOO_DAEMON":"1"}, "8080 example.com true"},
+  {"--host=localhost", map[string]string{"FOO_HOST":"foo"}, "1234 localhost false"},
+  {"", map[string]string{"FOO_PORT":"eighty"}, "Option '--port (from $FOO_PORT)' requires an integer as argument"},
+  {"", map[string]string{"FOO_DAEMON":"maybe"}, "Option --daemon (from $FOO_DAEMON) takes no argument"},
+}
+
+func main() {
+  config, err := argv.ReadConfig(strings.NewReader(configfile))
+  if err != nil {
+    fmt.Println(err)
+    os.Exit(1)
+  }
+  argv.Config = config
+  
+  for _, t := range tests {
+    for _, e := range env { os.Unsetenv(e) }
+    for k, v := range t.env { os.Setenv(k, v) }
+    
+    options, _, err, _ := argv.Parse(strings.Fields(t.args), usage, "")
+    var result string
+    if err != nil {
+      result = err.Error()
+    } else {
+      result = fmt.Sprintf("%v %v %v", options[PORT].Last().Value, options[HOST].Last().Arg, options[DAEMON].Is(1))
+    }
+    fmt.Printf("%-18v %v => %v ... ", t.args, t.env, result)
+    if result == t.expected { fmt.Println("OK") } else {
+      fmt.Printf("FAIL (expected %v)\n", t.expected)
+      os.Exit(1)
+    }
+  }
+  
+  for _, e := range env { os.Unsetenv(e) }
+  os.Setenv("FOO_PORT", "8080")
+  root := &argv.Command{Name: "foo", Usage: argv.Usage{{0, 0, "", "", argv.ArgUnknown, "USAGE: foo <command>"}},
+                        Commands: []*argv.Command{{Name: "daemon", Usage: usage}}}
+  _, options, _, err, _ := argv.ParseCommands([]string{"daemon", "-d"}, root, "")
+  fmt.Printf("ParseCommands() ... ")
+  if err == nil && options[1][PORT].Last().Value == 8080 && options[1][HOST].Last().Source == "config key host" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err)
+    os.Exit(1)
+  }
+  
+  calls := 0
+  counting := func(option *argv.Option) error { calls++; return argv.ArgRequired(option) }
+  counted := argv.Usage{
+  { 0, 0, "", "",      argv.ArgUnknown, "USAGE: foo [options]" },
+  { 1, 0, "", "user",  counting, "  \t--user=<name>  \tUser." },
+  { 2, 0, "", "group", argv.WithMeta(counting, argv.OptionMeta{Env:"FOO_GROUP", Key:"group"}), "  \t--group=<name>  \tGroup." },
+  }
+  os.Setenv("FOO_GROUP", "wheel")
+  opts, _, err, _ := argv.Parse(nil, counted, "")
+  fmt.Printf("ArgCheckers called only for synthesized options ... ")
+  if err == nil && calls == 1 && opts[1] == nil && opts[2].Arg == "wheel" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, calls)
+    os.Exit(1)
+  }
+}
@@ -0,0 +1,98 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-formatter.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "sync"
+         "../argv"
+       )
+
+var usage = argv.Usage{
+{ 0, 0, "", "",        argv.ArgUnknown,  "USAGE: test-formatter [options]\n\nOptions:" },
+{ 1, 0, "v","verbose", argv.ArgNone,     "  -v, \t--verbose  \tIncrease verbosity. This text is long enough to be wrapped at least once or twice." },
+{ 2, 0, "o","output",  argv.ArgRequired, "  -o <file>, \t--output=<file>  \tWrite output to <file>." },
+}
+
+func main() {
+  // Compute expected results the old way, with the package variables.
+  widths := []int{20, 40, 60, 80}
+  expected := map[int]string{}
+  for _, w := range widths {
+    argv.Columns = w
+    expected[w] = usage.String()
+  }
+  argv.Columns = 0
+  
+  for _, w := range widths {
+    f := &argv.Formatter{Columns: w, LastColumnMinPercent: 50, LastColumnOwnLineMaxPercent: 75}
+    fmt.Printf("%v ... ", fmt.Sprintf("Formatter{Columns: %v} matches Usage.String()", w))
+    if f.Format(usage) == expected[w] { fmt.Println("OK") } else {
+      fmt.Println("FAIL")
+      os.Exit(1)
+    }
+  }
+  
+  var wg sync.WaitGroup
+  errors := make(chan int, 1000)
+  for g := 0; g < 8; g++ {
+    wg.Add(1)
+    go func(w int) {
+      defer wg.Done()
+      f := &argv.Formatter{Columns: w, LastColumnMinPercent: 50, LastColumnOwnLineMaxPercent: 75}
+      for i := 0; i < 100; i++ {
+        if f.Format(usage) != expected[w] { errors <- w }
+      }
+    }(widths[g % len(widths)])
+  }
+  wg.Wait()
+  fmt.Printf("concurrent Formatters with different widths ... ")
+  if len(errors) == 0 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  
+  r, w, _ := os.Pipe()
+  fmt.Printf("TerminalWidth() of a pipe is 0 ... ")
+  if argv.TerminalWidth(r) == 0 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  os.Setenv("COLUMNS", "40")
+  f := argv.NewFormatter(w)
+  fmt.Printf("NewFormatter() falls back to $COLUMNS ... ")
+  if f.Columns == 40 && f.LastColumnMinPercent == 50 && f.LastColumnOwnLineMaxPercent == 75 && f.Style == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("NewFormatter() result matches Usage.String() ... ")
+  if f.Format(usage) == expected[40] { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  os.Setenv("COLUMNS", "")
+  fmt.Printf("NewFormatter() falls back to 80 ... ")
+  if argv.NewFormatter(w).Columns == 80 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  r.Close(); w.Close()
+  
+  if tty, err := os.Open("/dev/tty"); err == nil {
+    fmt.Printf("TerminalWidth(/dev/tty) = %v\n", argv.TerminalWidth(tty))
+    tty.Close()
+  }
+  
+  t := &argv.Table{Rows: [][]string{{"a", "one two three four five six"}}}
+  f = &argv.Formatter{Columns: 15, LastColumnMinPercent: 50, LastColumnOwnLineMaxPercent: 75}
+  fmt.Printf("Formatter.FormatTable() ... ")
+  if f.FormatTable(t) == "a  one two\n   three four\n   five six\n" { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+}
@@ -0,0 +1,206 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-genericdeque.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt"
+         "time"
+         "math/rand"
+         "../deque"
+       )
+
+func equal(d *deque.Of[int], model []int) bool {
+  d.CheckInvariant()
+  if d.Count() != len(model) { return false }
+  for i := range model {
+    if d.At(i) != model[i] || d.Peek(len(model)-1-i) != model[i] { return false }
+  }
+  return true
+}
+
+func main() {
+  // random operations compared against a slice
+  var d deque.Of[int]
+  d.Growth = deque.GrowBy(3)
+  model := []int{}
+  ok := true
+  for i := 0; i < 5000 && ok; i++ {
+    n := len(model)
+    switch rand.Intn(6) {
+      case 0: d.Push(i); model = append(model, i)
+      case 1: d.Insert(i); model = append([]int{i}, model...)
+      case 2: idx := rand.Intn(n+1)
+              d.InsertAt(idx, i)
+              model = append(model[:idx], append([]int{i}, model[idx:]...)...)
+      case 3: if n > 0 {
+                idx := rand.Intn(n)
+                ok = d.RemoveAt(idx) == model[idx]
+                model = append(model[:idx], model[idx+1:]...)
+              }
+      case 4: if n > 0 {
+                idx := rand.Intn(n)
+                ok = d.PopAt(idx) == model[n-1-idx]
+                model = append(model[:n-1-idx], model[n-idx:]...)
+              }
+      case 5: if n > 0 {
+                idx := rand.Intn(n)
+                ok = d.Put(idx, -i) == model[idx]
+                model[idx] = -i
+              }
+    }
+    ok = ok && equal(&d, model)
+  }
+  fmt.Printf("random insertions and removals ... ")
+  if ok { fmt.Println("OK") } else {
+    fmt.Println("FAIL", d.String())
+    os.Exit(1)
+  }
+
+  d.Overcapacity(0)
+  fmt.Printf("Overcapacity(0) ... ")
+  if d.Capacity() == len(model) && equal(&d, model) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  ints := deque.NewOf[int]([]int{5,3,1}, []int{4,2})
+  fmt.Printf("NewOf() with items ... ")
+  if ints.String() == "Deque[5 3 1 4 2]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ints.String())
+    os.Exit(1)
+  }
+  fmt.Printf("out of range ... ")
+  if ints.At(5) == 0 && ints.Peek(-1) == 0 && ints.RemoveAt(7) == 0 && ints.Count() == 5 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("IndexOf/Contains ... ")
+  if ints.IndexOf(4) == 3 && ints.IndexOf(7) == -1 && ints.Contains(1) && !ints.Contains(0) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  ints.Sort(func(a,b int) int { return a-b })
+  fmt.Printf("Sort ... ")
+  if ints.String() == "Deque[1 2 3 4 5]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ints.String())
+    os.Exit(1)
+  }
+  fmt.Printf("InsertSorted ... ")
+  if ints.InsertSorted(3, func(a,b int) int { return a-b }) == 2 && ints.String() == "Deque[1 2 3 3 4 5]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ints.String())
+    os.Exit(1)
+  }
+  ints.Reverse()
+  fmt.Printf("Reverse ... ")
+  if ints.String() == "Deque[5 4 3 3 2 1]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ints.String())
+    os.Exit(1)
+  }
+  fmt.Printf("Swap ... ")
+  if ints.Swap(0,5) != nil && ints.Swap(0,6) == nil && ints.String() == "Deque[1 4 3 3 2 5]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ints.String())
+    os.Exit(1)
+  }
+  fmt.Printf("Remove ... ")
+  if ints.Remove(3) == 2 && ints.String() == "Deque[1 4 2 5]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ints.String())
+    os.Exit(1)
+  }
+
+  type pair struct { key int; val string }
+  pairs := deque.NewOf[pair]()
+  for i, s := range []string{"a","b","c","d","e","f"} { pairs.Insert(pair{i%2, s}) }
+  pairs.Sort(func(a,b pair) int { return a.key-b.key })
+  fmt.Printf("Sort is stable ... ")
+  if fmt.Sprint(pairs) == "Deque[{0 e} {0 c} {0 a} {1 f} {1 d} {1 b}]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", pairs.String())
+    os.Exit(1)
+  }
+
+  mru := deque.NewOf[string](3, deque.DropFarEndIfOverflow)
+  for _, s := range []string{"a","b","c","d"} { mru.Push(s) }
+  fmt.Printf("DropFarEndIfOverflow ... ")
+  if mru.String() == "Deque[b c d]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", mru.String())
+    os.Exit(1)
+  }
+
+  discard := deque.NewOf[string](2, deque.DropItemIfOverflow)
+  fmt.Printf("DropItemIfOverflow ... ")
+  if discard.Push("a") && discard.Push("b") && !discard.Push("c") && discard.String() == "Deque[a b]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", discard.String())
+    os.Exit(1)
+  }
+
+  // producer-consumer with BlockIfFull
+  queue := deque.NewOf[int](4, deque.BlockIfFull)
+  go func() {
+    for i := 1; i <= 100; i++ { queue.Push(i) }
+    queue.Push(0)
+  }()
+  sum := 0
+  for a := queue.Next(); a != 0; a = queue.Next() { sum += a }
+  fmt.Printf("BlockIfFull ... ")
+  if sum == 5050 && queue.Capacity() == 4 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", sum)
+    os.Exit(1)
+  }
+
+  fmt.Printf("WaitForItem timeout ... ")
+  if !queue.WaitForItem(10*time.Millisecond) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  go queue.Push(1)
+  fmt.Printf("WaitForItem ... ")
+  if queue.WaitForItem(0) && queue.Pop() == 1 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  for i := 0; i < 4; i++ { queue.Push(i) }
+  fmt.Printf("WaitForSpace timeout ... ")
+  if !queue.WaitForSpace(10*time.Millisecond) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  go queue.Next()
+  fmt.Printf("WaitForSpace ... ")
+  if queue.WaitForSpace(0) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  go func() { for !queue.IsEmpty() { queue.Pop() } }()
+  fmt.Printf("WaitForEmpty ... ")
+  if queue.WaitForEmpty(0) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  // the interface{} Deque shares the implementation
+  old := deque.New([]interface{}{3,1,2})
+  old.Sort(func(a,b interface{}) int { return a.(int)-b.(int) })
+  fmt.Printf("Deque.Sort ... ")
+  if old.String() == "Deque[1 2 3]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", old.String())
+    os.Exit(1)
+  }
+  fmt.Printf("Deque.IndexOf ... ")
+  if old.IndexOf(2) == 1 && old.Contains(3) && !old.Contains(4) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  old.Reverse().Swap(0,1)
+  fmt.Printf("Deque.Reverse/Swap ... ")
+  if old.String() == "Deque[2 3 1]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", old.String())
+    os.Exit(1)
+  }
+  old.CheckInvariant()
+}
@@ -0,0 +1,95 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-graphemes.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "bytes"
+         "strings"
+         "../argv"
+       )
+
+type widthtest struct {
+  text string
+  width int
+}
+
+var widthtests = []widthtest{
+  {"abc", 3},
+  {"e\u0301", 1},                                  // e + COMBINING ACUTE ACCENT
+  {"a\u0323\u0308b", 2},                           // multiple combining marks
+  {"世界", 4},
+  {"한국어", 6},
+  {"\u1112\u1161\u11AB", 2},                       // conjoining jamo forming 한
+  {"ｶﾀｶﾅ", 4},                                      // halfwidth katakana
+  {"ＡＢ", 4},                                        // fullwidth latin
+  {"a\u200Bb", 2},                                 // ZERO WIDTH SPACE
+  {"\U0001F468\u200D\U0001F469\u200D\U0001F467", 2}, // family emoji (ZWJ sequence)
+  {"\U0001F44D\U0001F3FD", 2},                     // thumbs up + skin tone modifier
+  {"\u2764\uFE0F", 2},                             // heart + VARIATION SELECTOR-16
+  {"\U0001F1E9\U0001F1EA\U0001F1EF\U0001F1F5", 4}, // flags DE and JP
+  {"\u0915\u093F", 1},                             // Devanagari KA + vowel sign I (spacing mark)
+}
+
+type wraptest struct {
+  text string
+  width int
+  expected string
+}
+
+var wraptests = []wraptest{
+  {"e\u0301e\u0301e\u0301e\u0301", 3, "e\u0301e\u0301e\u0301\ne\u0301\n"},
+  {"ab\U0001F468\u200D\U0001F469\u200D\U0001F467cd", 3, "ab\n\U0001F468\u200D\U0001F469\u200D\U0001F467c\nd\n"},
+  {"\U0001F1E9\U0001F1EA\U0001F1EF\U0001F1F5", 3, "\U0001F1E9\U0001F1EA\n\U0001F1EF\U0001F1F5\n"},
+  {"日本語のテキスト", 5, "日本\n語の\nテキ\nスト\n"},
+  {"x \u0301y", 2, "x \u0301\ny\n"},
+  {"한국어 도움말", 6, "한국어\n도움말\n"},
+}
+
+func fail(format string, args ...interface{}) {
+  fmt.Printf("FAIL (" + format + ")\n", args...)
+  os.Exit(1)
+}
+
+func main() {
+  for _, t := range widthtests {
+    fmt.Printf("ScreenLength(%q) ... ", t.text)
+    iter := argv.Usage{{0, 0, "", "", argv.ArgUnknown, t.text + "\tx"}}.Iterate()
+    iter.NextTable(); iter.NextRow(); iter.NextPart()
+    if iter.Data() != t.text { fail("Data() returned %q", iter.Data()) }
+    if iter.ScreenLength() != t.width { fail("got %v, expected %v", iter.ScreenLength(), t.width) }
+    fmt.Println("OK")
+  }
+  
+  for _, t := range wraptests {
+    fmt.Printf("Wrapping %q at width %v ... ", t.text, t.width)
+    var buf bytes.Buffer
+    w := argv.NewColumnWrapper(0, t.width)
+    w.Process(&buf, t.text)
+    buf.WriteString("\n")
+    w.Flush(&buf)
+    if buf.String() != t.expected { fail("got %q, expected %q", buf.String(), t.expected) }
+    fmt.Println("OK")
+  }
+  
+  fmt.Printf("Aligning table with Korean, combining marks and emoji ... ")
+  argv.Columns = 40
+  usage := argv.Usage{
+    {0, 0, "", "", argv.ArgUnknown, "  -a, \t--alpha  \tre\u0301sume\u0301"},
+    {1, 0, "", "", argv.ArgUnknown, "  -\u1112\u1161\u11AB, \t--도움말  \t\U0001F468\u200D\U0001F469\u200D\U0001F467 family"},
+    {2, 0, "", "", argv.ArgUnknown, "  -\u2764\uFE0F, \t--love  \tx"},
+  }
+  lines := strings.Split(strings.TrimRight(usage.String(), "\n"), "\n")
+  expected := []string{
+    "  -a,  --alpha   re\u0301sume\u0301",
+    "  -\u1112\u1161\u11AB, --도움말  \U0001F468\u200D\U0001F469\u200D\U0001F467 family",
+    "  -\u2764\uFE0F, --love    x",
+  }
+  if strings.Join(lines, "\n") != strings.Join(expected, "\n") { fail("got\n%v\nexpected\n%v", strings.Join(lines, "\n"), strings.Join(expected, "\n")) }
+  fmt.Println("OK")
+}
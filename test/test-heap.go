@@ -0,0 +1,160 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-heap.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt"
+         "time"
+         "sort"
+         "context"
+         "math/rand"
+         "../deque"
+       )
+
+type job struct {
+  prio int
+  name string
+}
+
+func main() {
+  intcmp := func(a,b interface{}) int { return a.(int)-b.(int) }
+  h := deque.NewHeap(intcmp)
+  for _, x := range []int{5, 3, 8, 1} { h.Push(x) }
+  h.CheckInvariant()
+  fmt.Printf("Push() ... ")
+  if h.String() == "Heap[1 3 5 8]" && h.Count() == 4 && h.Peek() == 1 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", h)
+    os.Exit(1)
+  }
+  h.Push(4)
+  out := ""
+  for !h.IsEmpty() { out += fmt.Sprint(h.Next()) }
+  fmt.Printf("Next() in order ... ")
+  if out == "13458" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", out)
+    os.Exit(1)
+  }
+  fmt.Printf("Peek() on empty Heap ... ")
+  if h.Peek() == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  // random operations compared against a sorted slice
+  ints := deque.NewHeapOf(func(a,b int) int { return a-b })
+  handles := map[*deque.Handle]bool{}
+  ok := true
+  for i := 0; i < 3000 && ok; i++ {
+    switch rand.Intn(4) {
+      case 0, 1: handles[ints.Push(rand.Intn(100))] = true
+      case 2: for hd := range handles {
+                v, _ := ints.At(hd)
+                x, removed := ints.Remove(hd)
+                ok = removed && x == v
+                delete(handles, hd)
+                _, removed = ints.Remove(hd)
+                ok = ok && !removed
+                break
+              }
+      case 3: for hd := range handles {
+                ok = ints.Update(hd, rand.Intn(100))
+                break
+              }
+    }
+    ints.CheckInvariant()
+  }
+  fmt.Printf("random Push/Remove/Update ... ")
+  if ok && ints.Count() == len(handles) { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ints.Count(), len(handles))
+    os.Exit(1)
+  }
+  vals := []int{}
+  for hd := range handles { v, _ := ints.At(hd); vals = append(vals, v) }
+  sort.Ints(vals)
+  for i := 0; !ints.IsEmpty(); i++ { ok = ok && ints.Next() == vals[i] }
+  fmt.Printf("Next() after random operations ... ")
+  if ok { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  for hd := range handles { _, ok = ints.At(hd); if ok { break } }
+  fmt.Printf("handles invalid after Next() ... ")
+  if !ok { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  jobs := deque.NewHeapOf(func(a,b job) int { return a.prio - b.prio })
+  jobs.Push(job{2, "a"})
+  hb := jobs.Push(job{2, "b"})
+  jobs.Push(job{2, "c"})
+  jobs.Push(job{1, "d"})
+  fmt.Printf("equal items in FIFO order ... ")
+  if jobs.String() == "Heap[{1 d} {2 a} {2 b} {2 c}]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", jobs)
+    os.Exit(1)
+  }
+  jobs.Update(hb, job{0, "b"})
+  fmt.Printf("Update() priority ... ")
+  if jobs.Next().name == "b" && jobs.Next().name == "d" && jobs.Next().name == "a" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", jobs)
+    os.Exit(1)
+  }
+  fmt.Printf("Update() after Next() ... ")
+  if !jobs.Update(hb, job{0, "b"}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  jobs.Clear()
+  fmt.Printf("Clear() ... ")
+  if jobs.IsEmpty() { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  other := deque.NewHeap(intcmp)
+  hx := h.Push(1)
+  _, removed := other.Remove(hx)
+  fmt.Printf("handle of other Heap ... ")
+  if !removed && h.Count() == 1 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  h.Next()
+
+  fmt.Printf("WaitForItem() timeout ... ")
+  if !h.WaitForItem(10*time.Millisecond) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  go h.Push(42)
+  fmt.Printf("Next() blocks until Push() ... ")
+  if h.Next() == 42 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+  defer cancel()
+  x, err := h.NextCtx(ctx)
+  fmt.Printf("NextCtx() deadline ... ")
+  if x == nil && err == context.DeadlineExceeded { fmt.Println("OK") } else {
+    fmt.Println("FAIL", x, err)
+    os.Exit(1)
+  }
+  h.CheckInvariant()
+
+  h.Push(1)
+  go h.Next()
+  fmt.Printf("WaitForEmpty() ... ")
+  if h.WaitForEmpty(0) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  h.CheckInvariant()
+}
@@ -0,0 +1,111 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-iterator.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt"
+         "../deque"
+       )
+
+func main() {
+  var empty deque.Deque
+  it := empty.Iterator()
+  fmt.Printf("empty Deque ... ")
+  if !it.Next() && it.Index() == -1 && it.Value() == nil && it.Err() == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  d := deque.New([]interface{}{"a","b","c"})
+  out := ""
+  for it := d.Iterator(); it.Next(); {
+    out += fmt.Sprintf("%v%v ", it.Index(), it.Value())
+  }
+  fmt.Printf("forward ... ")
+  if out == "0a 1b 2c " { fmt.Println("OK") } else {
+    fmt.Println("FAIL", out)
+    os.Exit(1)
+  }
+
+  out = ""
+  for it := d.Iterator(deque.REVERSE|deque.LIVE); it.Next(); {
+    out += fmt.Sprintf("%v%v ", it.Index(), it.Value())
+  }
+  fmt.Printf("reverse live ... ")
+  if out == "2c 1b 0a " { fmt.Println("OK") } else {
+    fmt.Println("FAIL", out)
+    os.Exit(1)
+  }
+
+  out = ""
+  for x := range d.All() {
+    out += x.(string)
+    d.Push(x) // snapshot is unaffected
+  }
+  fmt.Printf("All() over snapshot ... ")
+  if out == "abc" && d.Count() == 6 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", out, d)
+    os.Exit(1)
+  }
+
+  out = ""
+  for x := range d.Backward() {
+    out += x.(string)
+    if len(out) == 2 { break }
+  }
+  fmt.Printf("Backward() with break ... ")
+  if out == "cb" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", out)
+    os.Exit(1)
+  }
+
+  it = d.Iterator(deque.LIVE)
+  it.Next()
+  d.Put(1, "B")
+  it.Next()
+  fmt.Printf("live iterator sees Put() ... ")
+  if it.Value() == "B" && it.Err() == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL", it.Value())
+    os.Exit(1)
+  }
+  d.Pop()
+  fmt.Printf("live iterator detects modification ... ")
+  if !it.Next() && it.Err() == deque.ConcurrentModification && it.Index() == -1 && !it.Next() { fmt.Println("OK") } else {
+    fmt.Println("FAIL", it.Err())
+    os.Exit(1)
+  }
+
+  ints := deque.NewOf[int]([]int{1,2,3,4})
+  sum := 0
+  for x := range ints.All() { sum += x }
+  fmt.Printf("Of[T].All() ... ")
+  if sum == 10 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", sum)
+    os.Exit(1)
+  }
+
+  lit := ints.Iterator(deque.LIVE, deque.REVERSE)
+  out = ""
+  for x := range lit.Values() {
+    out += fmt.Sprint(x)
+    if x == 3 { ints.Sort(func(a,b int) int { return b-a }) }
+  }
+  fmt.Printf("Of[T] live Values() stops on Sort() ... ")
+  if out == "43" && lit.Err() == deque.ConcurrentModification { fmt.Println("OK") } else {
+    fmt.Println("FAIL", out)
+    os.Exit(1)
+  }
+
+  out = ""
+  for x := range ints.Backward() { out += fmt.Sprint(x) }
+  fmt.Printf("Of[T].Backward() ... ")
+  if out == "1234" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", out)
+    os.Exit(1)
+  }
+}
@@ -0,0 +1,156 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-meta.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "strings"
+         "../argv"
+       )
+
+const (
+  UNKNOWN = iota
+  VERBOSE
+  OUTPUT
+  FORMAT
+  DEBUG
+  OLDOUT
+  COLOR
+)
+
+var output = argv.OptionMeta{Group: "Output"}
+var display = argv.OptionMeta{Group: "Display"}
+
+var usage = argv.Usage{
+{ UNKNOWN, 0, "", "",      argv.ArgUnknown,  "USAGE: test-meta [options]\n\nOptions:" },
+{ VERBOSE, 0, "v","verbose", argv.ArgNone,   "  -v, \t--verbose  \tIncrease verbosity." },
+{ OUTPUT, 0, "o","output", argv.WithMeta(argv.ArgRequired, output), "  -o <file>, \t--output=<file>  \tWrite output to <file>." },
+{ FORMAT, 0, "f","format", argv.WithMeta(argv.ArgEnum("text", "json"), output), "  -f <fmt>, \t--format=<fmt>  \tOutput format." },
+{ DEBUG, 0, "", "debug-internals", argv.WithMeta(argv.ArgNone, argv.OptionMeta{Hidden: true}), "  \t--debug-internals  \tDump internal state." },
+{ OLDOUT, 0, "O","", argv.WithMeta(argv.ArgRequired, argv.OptionMeta{Group: "Output", Deprecated: "-O is deprecated, use --output instead"}),
+                                             "  -O <file>  \tSame as --output." },
+{ COLOR, 1, "", "color",   argv.WithMeta(argv.ArgNone, display), "  \t--color  \tColorize output." },
+{ COLOR, 0, "", "no-color", argv.WithMeta(argv.ArgNone, argv.OptionMeta{Group: "Display", Deprecated: "--no-color is deprecated, set NO_COLOR instead"}),
+                                             "  \t--no-color  \tDon't colorize output." },
+}
+
+var expected = `USAGE: test-meta [options]
+
+Options:
+  -v, --verbose  Increase verbosity.
+
+Output:
+  -o <file>, --output=<file>  Write output to <file>.
+  -f <fmt>,  --format=<fmt>   Output format.
+  -O <file>  Same as --output.
+
+Display:
+  --color     Colorize output.
+  --no-color  Don't colorize output.
+`
+
+func main() {
+  argv.Columns = 80
+  help := usage.String()
+  fmt.Print(help)
+  fmt.Printf("grouped help ... ")
+  if help == expected { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("hidden option not shown ... ")
+  if !strings.Contains(help, "debug") { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  troff, markdown := usage.Troff(), usage.Markdown()
+  fmt.Printf("hidden option not in Troff() and Markdown() ... ")
+  if !strings.Contains(troff, "debug") && !strings.Contains(markdown, "debug") && strings.Contains(troff, "Display:") { fmt.Println("OK") } else {
+    fmt.Println("FAIL", troff, markdown)
+    os.Exit(1)
+  }
+  script, _ := usage.CompletionScript("bash", "test-meta", "")
+  fmt.Printf("hidden option not completed ... ")
+  if !strings.Contains(script, "debug") && strings.Contains(script, "--no-color") { fmt.Println("OK") } else {
+    fmt.Println("FAIL", script)
+    os.Exit(1)
+  }
+  fmt.Printf("Meta() ... ")
+  if usage[FORMAT].Meta() == output && usage[VERBOSE].Meta() == (argv.OptionMeta{}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  options, _, err, _ := argv.Parse([]string{"--format=json"}, usage, "")
+  fmt.Printf("WithMeta() keeps ArgChecker ... ")
+  if err == nil && options[FORMAT].Value == "json" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err)
+    os.Exit(1)
+  }
+  
+  warnings := []string{}
+  argv.Warn = func(option *argv.Option, message string) {
+    warnings = append(warnings, option.Name + ": " + message)
+  }
+  options, _, err, _ = argv.Parse([]string{"-O", "x", "--debug-internals", "--no-color", "--color", "-Oy"}, usage, "gnu")
+  fmt.Printf("deprecated and hidden options are parsed ... ")
+  if err == nil && options[OLDOUT].Count() == 2 && options[DEBUG] != nil && options[COLOR].Last().Info.State == 1 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("warnings for deprecated options ... ")
+  if strings.Join(warnings, "|") == "-O: -O is deprecated, use --output instead|--no-color: --no-color is deprecated, set NO_COLOR instead|-O: -O is deprecated, use --output instead" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", warnings)
+    os.Exit(1)
+  }
+  
+  warnings = nil
+  _, _, err, _ = argv.Parse([]string{"-O", "x", "--bogus"}, usage, "gnu")
+  fmt.Printf("no warnings if Parse() fails ... ")
+  if err != nil && len(warnings) == 0 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  
+  root := &argv.Command{Name: "tool", Usage: usage[:2], Commands: []*argv.Command{{Name: "sub", Usage: usage}}}
+  _, _, _, err, _ = argv.ParseCommands([]string{"sub", "--no-color"}, root, "")
+  fmt.Printf("warnings from ParseCommands() ... ")
+  if err == nil && len(warnings) == 1 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, warnings)
+    os.Exit(1)
+  }
+  
+  argv.Warn = nil
+  _, _, err, _ = argv.Parse([]string{"-O", "x"}, usage, "gnu")
+  fmt.Printf("nil Warn ... ")
+  if err == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  
+  fmt.Printf("WithMeta() of the same ArgChecker ... ")
+  if usage[OUTPUT].Meta() == output && usage[OLDOUT].Meta().Deprecated != "" { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  
+  calls := 0
+  counting := func(option *argv.Option) error { calls++; return argv.ArgNone(option) }
+  counted := argv.Usage{
+  { 0, 0, "", "",      argv.ArgUnknown, "USAGE: test-meta [options]" },
+  { 1, 0, "a","all",   counting,        "  -a, \t--all  \tAll." },
+  { 2, 0, "b","both",  argv.WithMeta(counting, argv.OptionMeta{Group: "Misc", Env: "TEST_META_UNSET"}), "  -b, \t--both  \tBoth." },
+  }
+  argv.Parse([]string{"--al"}, counted, "")
+  counted.String()
+  counted.Markdown()
+  fmt.Printf("Meta() does not call ArgCheckers ... ")
+  if calls == 0 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", calls)
+    os.Exit(1)
+  }
+}
@@ -0,0 +1,174 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-nextany.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt"
+         "time"
+         "context"
+         "runtime"
+         "../deque"
+       )
+
+func main() {
+  var high, low deque.Deque
+
+  start := time.Now()
+  item, src := deque.NextAny(20*time.Millisecond, &high, &low)
+  fmt.Printf("timeout ... ")
+  if item == nil && src == nil && time.Since(start) >= 20*time.Millisecond { fmt.Println("OK") } else {
+    fmt.Println("FAIL", item, src)
+    os.Exit(1)
+  }
+  high.CheckInvariant()
+  low.CheckInvariant()
+
+  low.Push("l1")
+  high.Push("h1")
+  low.Push("l2")
+  item, src = deque.NextAny(0, &high, &low)
+  fmt.Printf("priority order ... ")
+  if item == "h1" && src == &high { fmt.Println("OK") } else {
+    fmt.Println("FAIL", item)
+    os.Exit(1)
+  }
+  item, src = deque.NextAny(0, &high, &low)
+  fmt.Printf("next non-empty Deque ... ")
+  if item == "l1" && src == &low { fmt.Println("OK") } else {
+    fmt.Println("FAIL", item)
+    os.Exit(1)
+  }
+
+  go high.Push("h2")
+  low.Next()
+  item, src = deque.NextAny(0, &high, &low, &high)
+  fmt.Printf("wait for item ... ")
+  if item == "h2" && src == &high { fmt.Println("OK") } else {
+    fmt.Println("FAIL", item)
+    os.Exit(1)
+  }
+  high.CheckInvariant()
+  low.CheckInvariant()
+
+  // many consumers, each item is delivered exactly once
+  results := make(chan interface{})
+  for i := 0; i < 4; i++ {
+    go func() {
+      for {
+        item, _ := deque.NextAny(0, &high, &low)
+        results <- item
+        if item == nil { return }
+      }
+    }()
+  }
+  sum := 0
+  for i := 1; i <= 100; i++ {
+    if i & 1 == 0 { high.Push(i) } else { low.Push(i) }
+  }
+  for i := 0; i < 100; i++ { sum += (<-results).(int) }
+  for i := 0; i < 4; i++ { low.Push(nil) }
+  for i := 0; i < 4; i++ { <-results }
+  fmt.Printf("concurrent consumers ... ")
+  if sum == 5050 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", sum)
+    os.Exit(1)
+  }
+  high.CheckInvariant()
+  low.CheckInvariant()
+
+  a := deque.NewOf[int]()
+  b := deque.NewOf[int]()
+  b.Push(7)
+  n, from := deque.NextAnyOf(0, a, b)
+  fmt.Printf("NextAnyOf() ... ")
+  if n == 7 && from == b { fmt.Println("OK") } else {
+    fmt.Println("FAIL", n)
+    os.Exit(1)
+  }
+  n, from = deque.NextAnyOf(time.Millisecond, a, b)
+  fmt.Printf("NextAnyOf() timeout ... ")
+  if n == 0 && from == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL", n)
+    os.Exit(1)
+  }
+
+  ctx, cancel := context.WithCancel(context.Background())
+  c := a.Chan(ctx)
+  a.Push(1)
+  a.Push(2)
+  a.Push(3)
+  tick := time.After(time.Second)
+  got := 0
+  select {
+    case got = <-c:
+    case <-tick:
+  }
+  fmt.Printf("Chan() in select ... ")
+  if got == 1 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", got)
+    os.Exit(1)
+  }
+  fmt.Printf("Chan() receive ... ")
+  if <-c == 2 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  // after cancel() the item held by the goroutine is either delivered or
+  // returned to the Of[T], but never lost
+  cancel()
+  rest := []int{}
+  for x := range c { rest = append(rest, x) }
+  for !a.IsEmpty() { rest = append(rest, a.Next()) }
+  fmt.Printf("Chan() closed on cancel, no item lost ... ")
+  if fmt.Sprint(rest) == "[3]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", rest)
+    os.Exit(1)
+  }
+
+  // the item held by the goroutine is returned even if the Of[T] is full
+  full := deque.NewOf[int](2, deque.DropItemIfOverflow)
+  ctx, cancel = context.WithCancel(context.Background())
+  c = full.Chan(ctx)
+  full.Push(1)
+  for !full.IsEmpty() { runtime.Gosched() }
+  full.Push(2)
+  full.Push(3)
+  cancel()
+  rest = []int{}
+  for x := range c { rest = append(rest, x) }
+  for !full.IsEmpty() { rest = append(rest, full.Next()) }
+  fmt.Printf("Chan() closed on cancel, full Of[T] ... ")
+  if fmt.Sprint(rest) == "[1 2 3]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", rest)
+    os.Exit(1)
+  }
+  
+  item, src = deque.NextAny(0)
+  fmt.Printf("NextAny() without deques ... ")
+  if item == nil && src == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  n, from = deque.NextAnyOf[int](0)
+  fmt.Printf("NextAnyOf() without deques ... ")
+  if n == 0 && from == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  
+  ctx, cancel = context.WithCancel(context.Background())
+  dc := low.Chan(ctx)
+  low.Push("x")
+  fmt.Printf("Deque.Chan() ... ")
+  if <-dc == "x" { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  cancel()
+  for range dc {}
+}
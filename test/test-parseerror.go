@@ -0,0 +1,61 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-parseerror.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "strings"
+         "../argv"
+       )
+
+var usage = argv.Usage{
+{ 0, 1, "", "",       argv.ArgUnknown, "USAGE: test-parseerror [options]" },
+{ 1, 1, "v","verbose",argv.ArgNone,    "  -v, \t--verbose  \tIncrease verbosity." },
+{ 2, 1, "", "version",argv.ArgNone,    "  \t--version  \tPrint version." },
+{ 3, 1, "n","numeric",argv.ArgInt,     "  -n <num>, \t--numeric=<num>  \tRequires a number as argument." },
+{ 4, 1, "", "number", argv.ArgInt,     "  \t--number=<num>  \tAnother number." },
+}
+
+type test struct {
+  args string
+  flags string
+  expected string
+}
+
+var kinds = []string{"UNKNOWN_OPTION", "AMBIGUOUS_OPTION", "MISSING_ARGUMENT", "UNEXPECTED_ARGUMENT", "INVALID_ARGUMENT"}
+
+var tests = []test{
+  {"-v --verbsoe", "", "UNKNOWN_OPTION 1 --verbsoe [--verbose]: Unknown option '--verbsoe'. Did you mean --verbose?"},
+  {"--versoin=1", "", "UNKNOWN_OPTION 0 --versoin=1 [--version]: Unknown option '--versoin'. Did you mean --version?"},
+  {"--verbos", "", "UNKNOWN_OPTION 0 --verbos [--verbose]: Unknown option '--verbos'. Did you mean --verbose?"},
+  {"--numbre", "", "UNKNOWN_OPTION 0 --numbre [--number]: Unknown option '--numbre'. Did you mean --number?"},
+  {"--xyzzy", "", "UNKNOWN_OPTION 0 --xyzzy []: Unknown option '--xyzzy'"},
+  {"-vx", "", "UNKNOWN_OPTION 0 -vx []: Unknown option '-x'"},
+  {"-xerbose", "-perl", "UNKNOWN_OPTION 0 -xerbose [--verbose]: Unknown option '-xerbose'. Did you mean --verbose?"},
+  {"-verbsoe", "-perl", "UNKNOWN_OPTION 0 -verbsoe []: Unknown option '-e'"},
+  {"--num=1", "--abb", "AMBIGUOUS_OPTION 0 --num=1 []: Ambiguous abbreviation '--num'. Candiates: --numeric, --number"},
+  {"-v -n", "", "MISSING_ARGUMENT 1 -n []: Option '-n' requires an integer as argument"},
+  {"-n -v", "", "MISSING_ARGUMENT 0 -n []: Option '-n' requires an integer as argument"},
+  {"--version=1", "", "UNEXPECTED_ARGUMENT 0 --version=1 []: Option --version takes no argument"},
+  {"file -n x", "gnu", "INVALID_ARGUMENT 1 -n []: Option '-n' requires an integer as argument"},
+}
+
+func main() {
+  for _, t := range tests {
+    _, _, err, _ := argv.Parse(strings.Fields(t.args), usage, t.flags)
+    result := "OK"
+    if perr, ok := err.(*argv.ParseError); ok {
+      result = fmt.Sprintf("%v %v %v %v: %v", kinds[perr.Kind], perr.Index, perr.Word, perr.Suggestions, perr)
+    }
+    fmt.Printf("%-12v => %v ... ", t.args, result)
+    if result == t.expected { fmt.Println("OK") } else {
+      fmt.Printf("FAIL (expected %v)\n", t.expected)
+      os.Exit(1)
+    }
+  }
+}
@@ -0,0 +1,263 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-persistent.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt"
+         "time"
+         "path/filepath"
+         "encoding/gob"
+         "../deque"
+       )
+
+type job struct {
+  Id int
+  Name string
+}
+
+func size(path string) int64 {
+  fi, err := os.Stat(path)
+  if err != nil { return -1 }
+  return fi.Size()
+}
+
+// Returns the path of a new copy of the log file at path. This is what
+// a new process would find if the process using the log had crashed.
+var crashes = 0
+func crash(path string) string {
+  data, err := os.ReadFile(path)
+  if err != nil { panic(err) }
+  crashes++
+  cpy := fmt.Sprintf("%v.crash%v", path, crashes)
+  if err = os.WriteFile(cpy, data, 0666); err != nil { panic(err) }
+  return cpy
+}
+
+func main() {
+  dir, err := os.MkdirTemp("", "test-persistent")
+  if err != nil { panic(err) }
+  defer os.RemoveAll(dir)
+  path := filepath.Join(dir, "jobs.log")
+
+  q, err := deque.OpenPersistentOf(path, deque.JSONCodec[job]{})
+  fmt.Printf("create ... ")
+  if err == nil && q.Count() == 0 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err)
+    os.Exit(1)
+  }
+  q.Push(job{1, "a"})
+  q.Push(job{2, "b"})
+  q.Insert(job{0, "z"})
+  q.InsertAt(2, job{3, "c"})
+  q.Push(job{4, "d"})
+  fmt.Printf("Next() ... ")
+  if (q.Next() == job{0, "z"}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL", q)
+    os.Exit(1)
+  }
+  fmt.Printf("Pop() ... ")
+  if (q.Pop() == job{4, "d"}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL", q)
+    os.Exit(1)
+  }
+
+  _, err = deque.OpenPersistentOf(path, deque.JSONCodec[job]{})
+  fmt.Printf("log in use ... ")
+  if err != nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  r, err := deque.OpenPersistentOf(crash(path), deque.JSONCodec[job]{})
+  fmt.Printf("replay ... ")
+  if err == nil && r.String() == q.String() && r.Count() == 3 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, r, q)
+    os.Exit(1)
+  }
+  r.CheckInvariant()
+
+  // a partially written record at the end is discarded
+  torn := crash(path)
+  f, _ := os.OpenFile(torn, os.O_WRONLY|os.O_APPEND, 0666)
+  f.Write([]byte{20, 0, 0, 0, 1, 2, 3, 4, 1, 0})
+  f.Close()
+  full := size(torn)
+  r, err = deque.OpenPersistentOf(torn, deque.JSONCodec[job]{})
+  fmt.Printf("torn record discarded ... ")
+  if err == nil && r.String() == q.String() && size(torn) == full-10 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, r)
+    os.Exit(1)
+  }
+  r.Push(job{5, "e"})
+  r, err = deque.OpenPersistentOf(crash(torn), deque.JSONCodec[job]{})
+  fmt.Printf("append after truncation ... ")
+  if (err == nil && r.Count() == 4 && r.Peek(0) == job{5, "e"}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, r)
+    os.Exit(1)
+  }
+
+  // a zero-filled tail (file extended, data never written) is discarded
+  zeros := crash(path)
+  f, _ = os.OpenFile(zeros, os.O_WRONLY|os.O_APPEND, 0666)
+  f.Write(make([]byte, 100))
+  f.Close()
+  r, err = deque.OpenPersistentOf(zeros, deque.JSONCodec[job]{})
+  fmt.Printf("zero-filled tail discarded ... ")
+  if err == nil && r.String() == q.String() && size(zeros) == full-10 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, r)
+    os.Exit(1)
+  }
+  
+  // a bad record in the middle is corruption, not a torn write
+  corrupt := crash(path)
+  data, _ := os.ReadFile(corrupt)
+  data[10] ^= 0xff // body of the 1st record
+  os.WriteFile(corrupt, data, 0666)
+  _, err = deque.OpenPersistentOf(corrupt, deque.JSONCodec[job]{})
+  fmt.Printf("corrupt record in the middle ... ")
+  if err != nil && size(corrupt) == int64(len(data)) { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, size(corrupt), len(data))
+    os.Exit(1)
+  }
+  
+  before := size(path)
+  fmt.Printf("Compact() ... ")
+  if q.Compact() == nil && size(path) < before { fmt.Println("OK") } else {
+    fmt.Println("FAIL", size(path), before)
+    os.Exit(1)
+  }
+  _, err = deque.OpenPersistentOf(path, deque.JSONCodec[job]{})
+  fmt.Printf("compacted log in use ... ")
+  if err != nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  q.Clear()
+  q.Push(job{6, "f"})
+  fmt.Printf("Close() ... ")
+  if q.Close() == nil && q.Err() == os.ErrClosed { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  r, err = deque.OpenPersistentOf(path, deque.JSONCodec[job]{})
+  fmt.Printf("Clear() is replayed ... ")
+  if err == nil && r.String() == "Deque[{6 f}]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, r)
+    os.Exit(1)
+  }
+  r.Close()
+
+  // a complete record that can't be decoded is an error, not a torn record
+  before_data, _ := os.ReadFile(path)
+  _, err = deque.OpenPersistentOf(path, deque.JSONCodec[int]{})
+  after_data, _ := os.ReadFile(path)
+  fmt.Printf("wrong Codec ... ")
+  if err != nil && string(after_data) == string(before_data) { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, len(before_data), len(after_data))
+    os.Exit(1)
+  }
+  r, err = deque.OpenPersistentOf(path, deque.JSONCodec[job]{})
+  fmt.Printf("log intact after wrong Codec ... ")
+  if err == nil && r.String() == "Deque[{6 f}]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, r)
+    os.Exit(1)
+  }
+  r.Close()
+
+  // automatic compaction
+  path2 := filepath.Join(dir, "ints.log")
+  ints, _ := deque.OpenPersistentOf(path2, deque.GobCodec[int]{})
+  ints.CompactAfter = 10
+  for i := 0; i < 100; i++ {
+    ints.Push(i)
+    ints.Next()
+  }
+  ints.Push(42)
+  small := size(path2)
+  fmt.Printf("automatic compaction ... ")
+  if small > 0 && small < 1000 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", small)
+    os.Exit(1)
+  }
+  ints2, _ := deque.OpenPersistentOf(crash(path2), deque.GobCodec[int]{})
+  fmt.Printf("replay after compaction ... ")
+  if ints2.String() == "Deque[42]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ints2)
+    os.Exit(1)
+  }
+
+  // overflow policies are logged correctly
+  path3 := filepath.Join(dir, "mru.log")
+  mru, _ := deque.OpenPersistentOf(path3, deque.JSONCodec[string]{}, 3, deque.DropFarEndIfOverflow)
+  for _, s := range []string{"a","b","c","d"} { mru.Push(s) }
+  mru.Insert("x")
+  mru2, _ := deque.OpenPersistentOf(crash(path3), deque.JSONCodec[string]{}, 3, deque.DropFarEndIfOverflow)
+  fmt.Printf("DropFarEndIfOverflow ... ")
+  if mru.String() == "Deque[x b c]" && mru2.String() == mru.String() { fmt.Println("OK") } else {
+    fmt.Println("FAIL", mru, mru2)
+    os.Exit(1)
+  }
+
+  // interface{} items with gob
+  gob.Register(job{})
+  path4 := filepath.Join(dir, "any.log")
+  anyq, err := deque.OpenPersistent(path4, deque.GobCodec[interface{}]{}, 2, deque.BlockIfFull)
+  fmt.Printf("OpenPersistent() ... ")
+  if err == nil && anyq.Capacity() == 2 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err)
+    os.Exit(1)
+  }
+  go func() {
+    for i := 0; i < 5; i++ { anyq.Push(job{i, "x"}) }
+    anyq.Push("done")
+  }()
+  n := 0
+  for x := anyq.Next(); x != "done"; x = anyq.Next() { n += x.(job).Id }
+  fmt.Printf("producer-consumer with BlockIfFull ... ")
+  if n == 10 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", n)
+    os.Exit(1)
+  }
+  fmt.Printf("WaitForItem() timeout ... ")
+  if !anyq.WaitForItem(10*time.Millisecond) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  anyq.Push(job{7, "y"})
+  anyq2, _ := deque.OpenPersistent(crash(path4), deque.GobCodec[interface{}]{})
+  fmt.Printf("replay interface{} items ... ")
+  if anyq2.String() == "Deque[{7 y}]" && anyq2.Err() == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL", anyq2)
+    os.Exit(1)
+  }
+
+  // an item the Codec can not encode is kept in memory and reported by Err()
+  path5 := filepath.Join(dir, "unencodable.log")
+  anyj, _ := deque.OpenPersistent(path5, deque.JSONCodec[interface{}]{})
+  anyj.Push(1)
+  fmt.Printf("unencodable item ... ")
+  if anyj.Push(make(chan int)) && anyj.Count() == 2 && anyj.Err() != nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL", anyj.Err())
+    os.Exit(1)
+  }
+  anyj.Pop()
+  anyj.Push(2)
+  anyj2, err := deque.OpenPersistent(crash(path5), deque.JSONCodec[interface{}]{})
+  fmt.Printf("log recovers after unencodable item is removed ... ")
+  if anyj.Err() == nil && err == nil && anyj2.String() == "Deque[1 2]" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", anyj.Err(), err, anyj2)
+    os.Exit(1)
+  }
+  
+  _, err = deque.OpenPersistent(filepath.Join(dir, "x.log"), deque.GobCodec[interface{}]{}, "bad")
+  fmt.Printf("unsupported argument ... ")
+  if err != nil && err.Error() == "Argument #3 is unsupported by deque.OpenPersistentOf()" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err)
+    os.Exit(1)
+  }
+}
@@ -0,0 +1,238 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-remote.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt"
+         "time"
+         "net"
+         "context"
+         "runtime"
+         "strings"
+         "path/filepath"
+         "../deque"
+       )
+
+type job struct {
+  Id int
+  Name string
+}
+
+func main() {
+  dir, err := os.MkdirTemp("", "test-remote")
+  if err != nil { panic(err) }
+  defer os.RemoveAll(dir)
+  sock := filepath.Join(dir, "deque.sock")
+
+  jobs := deque.NewOf[[]byte]()
+  small := deque.NewOf[[]byte](1, deque.BlockIfFull)
+  server := deque.NewServer()
+  server.MaxMessageSize = 1000
+  server.Export("jobs", jobs)
+  server.Export("small", small)
+  l, err := net.Listen("unix", sock)
+  if err != nil { panic(err) }
+  served := make(chan error)
+  go func() { served <- server.Serve(l) }()
+
+  err = deque.NewServer().ServeUnix(sock)
+  fmt.Printf("ServeUnix() on socket in use ... ")
+  if err != nil && err.Error() == sock+" is in use by another server" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err)
+    os.Exit(1)
+  }
+
+  codec := deque.JSONCodec[job]{}
+  producer, err := deque.DialOf(sock, "jobs", codec)
+  fmt.Printf("DialOf() ... ")
+  if err == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err)
+    os.Exit(1)
+  }
+  consumer, err := deque.DialOf(sock, "jobs", codec)
+  fmt.Printf("second client ... ")
+  if err == nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err)
+    os.Exit(1)
+  }
+  _, err = deque.DialOf(sock, "nope", codec)
+  fmt.Printf("unknown name ... ")
+  if err != nil && err.Error() == "Unknown Deque 'nope'" { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err)
+    os.Exit(1)
+  }
+
+  producer.Push(job{1, "a"})
+  producer.Push(job{2, "b"})
+  producer.Push(job{3, "c"})
+  fmt.Printf("Count() ... ")
+  if consumer.Count() == 3 && jobs.Count() == 3 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("Next() ... ")
+  if (consumer.Next() == job{1, "a"}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("Pop() ... ")
+  if (consumer.Pop() == job{3, "c"}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("Next() again ... ")
+  if (consumer.Next() == job{2, "b"} && consumer.IsEmpty()) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  fmt.Printf("WaitForItem() timeout ... ")
+  if !consumer.WaitForItem(20*time.Millisecond) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  go producer.Push(job{4, "d"})
+  fmt.Printf("WaitForItem() ... ")
+  if consumer.WaitForItem(0) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("Next() after WaitForItem() ... ")
+  if (consumer.Next() == job{4, "d"}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  go producer.Push(job{5, "e"})
+  fmt.Printf("blocking Next() ... ")
+  if (consumer.Next() == job{5, "e"}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+  defer cancel()
+  j, err := consumer.NextCtx(ctx)
+  fmt.Printf("NextCtx() deadline ... ")
+  if (err == context.DeadlineExceeded && j == job{}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err, j)
+    os.Exit(1)
+  }
+  producer.Push(job{6, "f"})
+  fmt.Printf("client usable after cancellation ... ")
+  if (consumer.Next() == job{6, "f"} && consumer.Err() == nil) { fmt.Println("OK") } else {
+    fmt.Println("FAIL", consumer.Err())
+    os.Exit(1)
+  }
+
+  // A client that disconnects while blocked must not swallow an item. Depending
+  // on timing the server either cancels the quitter's Next() or fails to send
+  // the item and returns it to the Deque.
+  quitter, _ := deque.DialOf(sock, "jobs", codec)
+  go quitter.Next()
+  quitter.Close()
+  producer.Push(job{7, "g"})
+  fmt.Printf("disconnected client ... ")
+  if (consumer.Next() == job{7, "g"}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  // BlockIfFull on the server blocks the pushing client
+  sp, _ := deque.DialOf(sock, "small", deque.GobCodec[int]{})
+  sc, _ := deque.DialOf(sock, "small", deque.GobCodec[int]{})
+  sp.Push(1)
+  ctx2, cancel2 := context.WithTimeout(context.Background(), 20*time.Millisecond)
+  defer cancel2()
+  ok, err := sp.PushCtx(ctx2, 2)
+  fmt.Printf("PushCtx() on full Deque ... ")
+  if !ok && err == context.DeadlineExceeded { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ok, err)
+    os.Exit(1)
+  }
+  go sc.Next()
+  fmt.Printf("Push() after space ... ")
+  if sp.Push(3) && small.Count() == 1 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("server sees encoded item ... ")
+  if sc.Next() == 3 { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  // the server process can use the Deque directly
+  data, _ := codec.Encode(job{8, "h"})
+  jobs.Push(data)
+  fmt.Printf("local Push(), remote Next() ... ")
+  if (consumer.Next() == job{8, "h"}) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  limited, _ := deque.DialOf(sock, "jobs", codec)
+  limited.MaxMessageSize = 1000
+  data, _ = codec.Encode(job{13, strings.Repeat("y", 2000)})
+  jobs.Push(data)
+  _, err = limited.NextCtx(context.Background())
+  fmt.Printf("client MaxMessageSize ... ")
+  if err != nil && err.Error() == fmt.Sprintf("Message of %v bytes exceeds the limit of 1000 bytes", len(data)) && limited.Err() != nil { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err)
+    os.Exit(1)
+  }
+  
+  big, _ := deque.DialOf(sock, "jobs", codec)
+  huge := job{10, strings.Repeat("x", 2000)}
+  data, _ = codec.Encode(huge)
+  ok, err = big.PushCtx(context.Background(), huge)
+  fmt.Printf("MaxMessageSize ... ")
+  if !ok && err != nil && err.Error() == fmt.Sprintf("Message of %v bytes exceeds the limit of 1000 bytes", len(data)) && jobs.IsEmpty() { fmt.Println("OK") } else {
+    fmt.Println("FAIL", ok, err)
+    os.Exit(1)
+  }
+  fmt.Printf("disconnected after too large message ... ")
+  if !big.Push(job{11, "k"}) && big.Err() != nil && jobs.IsEmpty() { fmt.Println("OK") } else {
+    fmt.Println("FAIL", big.Err())
+    os.Exit(1)
+  }
+
+  go server.Close()
+  j = consumer.Next()
+  fmt.Printf("server Close() ... ")
+  if (<-served == nil && j == job{} && consumer.Err() != nil) { fmt.Println("OK") } else {
+    fmt.Println("FAIL", consumer.Err())
+    os.Exit(1)
+  }
+  fmt.Printf("client fails after server Close() ... ")
+  if (!producer.Push(job{9, "i"}) && producer.Err() != nil) { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  // a socket left over by a crashed server is replaced
+  stale := filepath.Join(dir, "stale.sock")
+  l, err = net.Listen("unix", stale)
+  if err != nil { panic(err) }
+  l.(*net.UnixListener).SetUnlinkOnClose(false)
+  l.Close()
+  server = deque.NewServer()
+  server.Export("jobs", jobs)
+  go func() { served <- server.ServeUnix(stale) }()
+  var client *deque.ClientOf[job]
+  for start := time.Now(); time.Since(start) < 5*time.Second; runtime.Gosched() {
+    if client, err = deque.DialOf(stale, "jobs", codec); err == nil { break }
+  }
+  fmt.Printf("ServeUnix() replaces stale socket ... ")
+  if err == nil && client.Push(job{12, "l"}) && jobs.Count() == 1 { fmt.Println("OK") } else {
+    fmt.Println("FAIL", err)
+    os.Exit(1)
+  }
+  server.Close()
+  <-served
+}
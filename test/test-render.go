@@ -0,0 +1,68 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-render.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "../argv"
+       )
+
+var usage = argv.Usage{
+{ 0, 1, "", "",          argv.ArgUnknown, "USAGE: test-render [options] <file>\n\nOptions:" },
+{ 1, 1, "c","create",    argv.ArgNone,    "  -c,\v--create  \tCreates\vsomething." },
+{ 2, 1, "k","kill",      argv.ArgNone,    "  -k, \t--kill  \tDestroys *everything*." },
+{ 3, 1, "n","numeric",   argv.ArgInt,     "  -n <num>, \t--numeric=<num>  \tRequires a number | as argument." },
+{ 0, 1, "", "",          argv.ArgUnknown, "\f" },
+{ 0, 1, "", "",          argv.ArgUnknown, "\nExamples:\n  test-render -c file\n.hidden line" },
+}
+
+var expectedTroff = `USAGE: test\-render [options] <file>
+.PP
+Options:
+.TP
+\-c, \-\-create
+Creates
+.br
+something.
+.TP
+\-k, \-\-kill
+Destroys *everything*.
+.TP
+\-n <num>, \-\-numeric=<num>
+Requires a number | as argument.
+.PP
+Examples:
+.br
+test\-render \-c file
+.br
+\&.hidden line
+`
+
+var expectedMarkdown = "USAGE: test-render \\[options\\] \\<file\\>\n" +
+"\n" +
+"Options:\n" +
+"\n" +
+"|   |   |   |\n" +
+"|---|---|---|\n" +
+"| `-c, --create` |  | Creates<br>something. |\n" +
+"| `-k,` | `--kill` | Destroys \\*everything\\*. |\n" +
+"| `-n <num>,` | `--numeric=<num>` | Requires a number \\| as argument. |\n" +
+"\n" +
+"Examples:  \n" +
+"test-render -c file  \n" +
+".hidden line\n"
+
+func main() {
+  for _, t := range [][]string{{"Troff", usage.Troff(), expectedTroff}, {"Markdown", usage.Markdown(), expectedMarkdown}} {
+    fmt.Printf("%v()...", t[0])
+    if t[1] == t[2] { fmt.Println("OK") } else {
+      fmt.Printf("FAIL\n%v\n", t[1])
+      os.Exit(1)
+    }
+  }
+}
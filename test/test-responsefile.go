@@ -0,0 +1,72 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-responsefile.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "io/ioutil"
+         "path/filepath"
+         "../argv"
+       )
+
+var usage = argv.Usage{
+{ 0, 1, "", "",       argv.ArgUnknown, "USAGE: test-responsefile [options]" },
+{ 1, 1, "v","verbose",argv.ArgNone,    "  -v, \t--verbose  \tIncrease verbosity." },
+{ 2, 1, "n","numeric",argv.ArgInt,     "  -n <num>, \t--numeric=<num>  \tRequires a number as argument." },
+{ 3, 1, "t","title",  argv.ArgRequired,"  -t <title>, \t--title=<title>  \tSets the title." },
+}
+
+var files = map[string]string{
+  "a.rsp": "-v # comment\n--title 'hello world' \"quoted \\\"string\\\"\"\\\n  continued @b.rsp\n",
+  "b.rsp": "-n 10\n\n  -v\n",
+  "bad.rsp": "-v\n-n\nforty-two\n",
+  "cycle1.rsp": "-v @cycle2.rsp\n",
+  "cycle2.rsp": "\n\n@cycle1.rsp\n",
+  "quote.rsp": "-v\n'unterminated\n",
+}
+
+type test struct {
+  args []string
+  expected string
+}
+
+var tests = []test{
+  {[]string{"-v", "@a.rsp", "file"}, "verbose=3 numeric=10 title=hello world [quoted \"string\" continued file]"},
+  {[]string{"@bad.rsp"}, "ERROR 0 bad.rsp:2: Option '-n' requires an integer as argument"},
+  {[]string{"-v", "@cycle1.rsp"}, "ERROR 1 cycle2.rsp:3: Response file cycle1.rsp includes itself"},
+  {[]string{"@quote.rsp"}, "ERROR 0 quote.rsp: Line 2: Unterminated ' quote"},
+  {[]string{"@missing.rsp"}, "ERROR 0 open missing.rsp: no such file or directory"},
+  {[]string{"@", "-n1", "-t", "@"}, "verbose=0 numeric=1 title=@ [@]"},
+}
+
+func main() {
+  dir, err := ioutil.TempDir("", "test-responsefile")
+  if err != nil { panic(err) }
+  defer os.RemoveAll(dir)
+  for name, content := range files {
+    ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+  }
+  os.Chdir(dir)
+  
+  for _, t := range tests {
+    options, nonoptions, err, _ := argv.Parse(t.args, usage, "gnu @file")
+    var result string
+    if perr, ok := err.(*argv.ParseError); ok {
+      result = fmt.Sprintf("ERROR %v %v", perr.Index, perr)
+    } else if err != nil {
+      result = err.Error()
+    } else {
+      result = fmt.Sprintf("verbose=%v numeric=%v title=%v %v", options[1].Count(), options[2].Last().Value, options[3].Last().Arg, nonoptions)
+    }
+    fmt.Printf("%v => %v ... ", t.args, result)
+    if result == t.expected { fmt.Println("OK") } else {
+      fmt.Printf("FAIL (expected %v)\n", t.expected)
+      os.Exit(1)
+    }
+  }
+}
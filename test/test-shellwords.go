@@ -0,0 +1,82 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-shellwords.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "reflect"
+         "../argv"
+       )
+
+var env = map[string]string{
+  "HOME": "/home/user",
+  "FOO": "a b",
+  "SPACED": " x  y ",
+  "EMPTY": "",
+  "QUOTE": "'\"",
+}
+
+func getenv(name string) string { return env[name] }
+
+type test struct {
+  line string
+  expand func(string) string
+  expected []string
+  err string
+}
+
+var tests = []test{
+  {`cp -v "my file" 'it'\''s' $HOME/`, getenv, []string{"cp", "-v", "my file", "it's", "/home/user/"}, ""},
+  {`x$FOO "$FOO" $EMPTY "$EMPTY" ''`, getenv, []string{"xa", "b", "a b", "", ""}, ""},
+  {`<$SPACED>`, getenv, []string{"<", "x", "y", ">"}, ""},
+  {`${HOME}x $HOMEx $ $1 "$" a$`, getenv, []string{"/home/userx", "$", "$1", "$", "a$"}, ""},
+  {`$QUOTE '$HOME' \$HOME "\$HOME"`, getenv, []string{"'\"", "$HOME", "$HOME", "$HOME"}, ""},
+  {`$HOME ${HOME}`, nil, []string{"$HOME", "${HOME}"}, ""},
+  {"a\\\n b # comment\n c", nil, []string{"a", "b", "c"}, ""},
+  {`"unterminated`, nil, nil, "Line 1: Unterminated \" quote"},
+  {`x ${HOME`, getenv, nil, "Line 1: Unterminated ${"},
+  {`x ${HOME:-default}`, getenv, nil, "Line 1: Bad substitution ${HOME:-default}"},
+  {`x ${}`, getenv, nil, "Line 1: Bad substitution ${}"},
+}
+
+var jointests = [][]string{
+  {"simple", "-x", "--foo=bar", "a/b.c", "user@host:1,2%+_"},
+  {"", "with space", "it's", "\"double\"", "$HOME", "back\\slash", "#comment", "~", "*", "new\nline", "tab\t", "日本"},
+}
+
+func fail(format string, args ...interface{}) {
+  fmt.Printf("FAIL (" + format + ")\n", args...)
+  os.Exit(1)
+}
+
+func main() {
+  for _, t := range tests {
+    fmt.Printf("%q ... ", t.line)
+    words, err := argv.SplitCommandLine(t.line, t.expand)
+    if t.err != "" {
+      if err == nil || err.Error() != t.err { fail("expected error %q, got %v", t.err, err) }
+    } else if err != nil || !reflect.DeepEqual(words, t.expected) {
+      fail("expected %q, got %q %v", t.expected, words, err)
+    }
+    fmt.Println("OK")
+  }
+  
+  for _, args := range jointests {
+    line := argv.JoinCommandLine(args)
+    fmt.Printf("%v ... ", line)
+    words, err := argv.SplitCommandLine(line, nil)
+    if err != nil || !reflect.DeepEqual(words, args) { fail("got %q %v", words, err) }
+    words, err = argv.SplitCommandLine(line, getenv)
+    if err != nil || !reflect.DeepEqual(words, args) { fail("with expansion got %q %v", words, err) }
+    fmt.Println("OK")
+  }
+  
+  fmt.Printf("Quote() ... ")
+  if argv.Quote("it's") != `'it'\''s'` || argv.Quote("") != "''" || argv.Quote("-x=1") != "-x=1" { fail("%v", argv.Quote("it's")) }
+  fmt.Println("OK")
+}
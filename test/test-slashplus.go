@@ -0,0 +1,91 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-slashplus.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "strings"
+         "../argv"
+       )
+
+const (
+  UNKNOWN = iota
+  FOO
+  OUTPUT
+  OUTLINE
+  VERBOSE
+)
+
+const (
+  DISABLED = iota
+  ENABLED
+)
+
+var usage = argv.Usage{
+{ UNKNOWN, 0, "", "",           argv.ArgUnknown, "USAGE: test-slashplus [options]" },
+{ FOO, ENABLED,  "f", "enable-foo",  argv.ArgNone,     "  -f, \t--enable-foo  \tEnable foo." },
+{ FOO, DISABLED, "",  "disable-foo", argv.ArgNone,     "  \t--disable-foo  \tDisable foo." },
+{ OUTPUT, 1,     "o", "output",      argv.ArgRequired, "  -o <file>, \t--output=<file>  \tOutput file." },
+{ OUTLINE, 1,    "",  "outline",     argv.ArgNone,     "  \t--outline  \tOutline mode." },
+{ VERBOSE, 1,    "v", "verbose",     argv.ArgNone,     "  -v, \t--verbose  \tIncrease verbosity." },
+}
+
+type test struct {
+  args []string
+  flags string
+  expected string
+}
+
+var tests = []test{
+  {[]string{"/output:x.txt", "/v", "/usr/bin"}, "/slash", "/output:x.txt /v:1 [/usr/bin]"},
+  {[]string{"/o=y"}, "/slash", "/o:y []"},
+  {[]string{"/o", "z", "/enable-foo"}, "/slash", "/o:z /enable-foo:1 []"},
+  {[]string{"/outp:a"}, "/slash --abb", "/outp:a []"},
+  {[]string{"/out:a"}, "/slash --abb", "ERROR /out:a: Ambiguous abbreviation '/out'. Candiates: /output, /outline"},
+  {[]string{"/out:a"}, "/slash", "[/out:a]"},
+  {[]string{"//server"}, "/slash", "[//server]"},
+  {[]string{"/output:x"}, "", "[/output:x]"},
+  {[]string{"-f", "+f"}, "+plus", "-f:1 +f:0 []"},
+  {[]string{"+enable-foo", "+disable-foo"}, "+plus", "+enable-foo:0 +disable-foo:1 []"},
+  {[]string{"+enable", "x"}, "+plus --abb", "+enable:0 [x]"},
+  {[]string{"+fv"}, "+plus", "ERROR +fv: Option '+v' cannot be negated"},
+  {[]string{"+f", "+"}, "", "[+f +]"},
+  {[]string{"+f", "/disable-foo"}, "+plus /slash", "+f:0 /disable-foo:0 []"},
+  {[]string{"/o:", "x"}, "/slash", "/o: [x]"},
+  {[]string{"/v:1"}, "/slash", "ERROR /v:1: Option /v takes no argument"},
+  {[]string{"+5", "+x"}, "+plus", "[+5 +x]"},
+  {[]string{"+5", "/x"}, "+plus /slash gnu", "[+5 /x]"},
+}
+
+func main() {
+  for _, t := range tests {
+    _, nonoptions, err, alloptions := argv.Parse(t.args, usage, t.flags)
+    var result string
+    if perr, ok := err.(*argv.ParseError); ok {
+      result = fmt.Sprintf("ERROR %v: %v", perr.Word, perr)
+    } else if err != nil {
+      result = err.Error()
+    } else {
+      words := []string{}
+      for _, o := range alloptions {
+        if o.HasArg {
+          words = append(words, fmt.Sprintf("%v:%v", o.Name, o.Arg))
+        } else {
+          words = append(words, fmt.Sprintf("%v:%v", o.Name, o.Info.State))
+        }
+      }
+      words = append(words, fmt.Sprintf("%v", nonoptions))
+      result = strings.Join(words, " ")
+    }
+    fmt.Printf("%v %q => %v ... ", t.args, t.flags, result)
+    if result == t.expected { fmt.Println("OK") } else {
+      fmt.Printf("FAIL (expected %v)\n", t.expected)
+      os.Exit(1)
+    }
+  }
+}
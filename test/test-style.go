@@ -0,0 +1,107 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-style.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "regexp"
+         "strings"
+         "../argv"
+       )
+
+var usage = argv.Usage{
+{ 0, 0, "", "",        argv.ArgUnknown,  "USAGE: test-style [options] <file>\n\nOptions:" },
+{ 1, 0, "v","verbose", argv.ArgNone,     "  -v, \t--verbose  \tIncrease verbosity. This text is long enough to be wrapped at least once or twice." },
+{ 2, 0, "o","output",  argv.ArgRequired, "  -o <file>, \t--output=<file>  \tWrite output to <file> instead of -stdout-." },
+{ 3, 0, "", "",        argv.ArgUnknown,  "\nExamples:\n  test-style -v --output=out.txt in.txt" },
+}
+
+var escape = regexp.MustCompile("\033\\[[0-9;]*m")
+
+func main() {
+  B, U, H, R := argv.ANSI.Option, argv.ANSI.Arg, argv.ANSI.Header, argv.ANSI.Reset
+  
+  for _, columns := range []int{80, 40, 20} {
+    argv.Columns = columns
+    plain := usage.String()
+    styled := usage.StyledString(argv.ANSI)
+    fmt.Print(styled)
+    fmt.Printf("%v ... ", fmt.Sprintf("Columns=%v: layout independent of escape sequences", columns))
+    if escape.ReplaceAllString(styled, "") == plain { fmt.Println("OK") } else {
+      fmt.Println("FAIL")
+      os.Exit(1)
+    }
+  }
+  
+  argv.Columns = 80
+  styled := usage.StyledString(argv.ANSI)
+  fmt.Printf("option names ... ")
+  if strings.Contains(styled, "  "+B+"-v"+R+", ") && strings.Contains(styled, B+"--verbose"+R+"  ") { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("argument placeholders ... ")
+  if strings.Contains(styled, B+"-o"+R+" "+U+"<file>"+R+", "+B+"--output"+R+"="+U+"<file>"+R+"  ") { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("description not highlighted ... ")
+  if strings.Contains(styled, "Write output to <file> instead of -stdout-.") { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("headers ... ")
+  if strings.Contains(styled, H+"USAGE:"+R+" test-style [options] <file>\n") && strings.Contains(styled, "\n"+H+"Options:"+R+"\n") && strings.Contains(styled, "\n"+H+"Examples:"+R+"\n") { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  fmt.Printf("plain line not highlighted ... ")
+  if strings.Contains(styled, "\n  test-style -v --output=out.txt in.txt\n") { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  
+  argv.HelpStyle = argv.ANSI
+  os.Setenv("NO_COLOR", "1")
+  fmt.Printf("NO_COLOR disables highlighting ... ")
+  if !strings.Contains(usage.String(), "\033") { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  os.Setenv("NO_COLOR", "")
+  r, w, _ := os.Pipe()
+  stdout := os.Stdout
+  os.Stdout = w
+  s := usage.String()
+  os.Stdout = stdout
+  w.Close(); r.Close()
+  fmt.Printf("non-terminal disables highlighting ... ")
+  if !strings.Contains(s, "\033") { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+
+  null, _ := os.Open(os.DevNull)
+  os.Stdout = null
+  s = usage.String()
+  os.Stdout = stdout
+  null.Close()
+  fmt.Printf("/dev/null disables highlighting ... ")
+  if !strings.Contains(s, "\033") { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+  
+  argv.Columns = 12
+  help := argv.Usage{{0,0,"","",argv.ArgUnknown, H+"0123456789ab"+R+"cdef"}}
+  fmt.Printf("ColumnWrapper keeps trailing escape sequence on the line ... ")
+  if help.StyledString(nil) == H+"0123456789ab"+R+"\ncdef\n" { fmt.Println("OK") } else {
+    fmt.Println("FAIL")
+    os.Exit(1)
+  }
+}
@@ -0,0 +1,112 @@
+/* Written 2015 by Matthias S. Benkmann
+ *
+ * The author hereby waives all copyright and related rights to the contents
+ * of this example file (test-table.go) to the extent possible under the law.
+ */
+
+package main
+
+import (
+         "os"
+         "fmt" 
+         "bytes"
+         "errors"
+         "../argv"
+       )
+
+type test struct {
+  name string
+  table *argv.Table
+  expected string
+}
+
+var tests = []test{
+  { "left/right alignment and wrapping",
+    &argv.Table{Width: 40, Format: []argv.ColumnFormat{{}, {Align: argv.ALIGN_RIGHT}}, Rows: [][]string{
+      {"NAME", "SIZE", "DESCRIPTION"},
+      {"foo.txt", "1234", "A text file."},
+      {"bar", "56", "A file with a description that is too long to fit."},
+    }},
+    "NAME     SIZE  DESCRIPTION\n" +
+    "foo.txt  1234  A text file.\n" +
+    "bar        56  A file with a description\n" +
+    "               that is too long to fit.\n",
+  },
+  { "center alignment, separator, minimum width",
+    &argv.Table{Width: 40, Separator: " | ", Format: []argv.ColumnFormat{{Align: argv.ALIGN_CENTER, MinWidth: 7}, {Align: argv.ALIGN_RIGHT}}, Rows: [][]string{
+      {"a", "1"},
+      {"bbb", "22"},
+      {"日本", "333"},
+    }},
+    "   a    |   1\n" +
+    "  bbb   |  22\n" +
+    " 日本   | 333\n",
+  },
+  { "maximum width of last column",
+    &argv.Table{Width: 40, Format: []argv.ColumnFormat{{}, {MaxWidth: 10}}, Rows: [][]string{
+      {"x", "one two three four five"},
+    }},
+    "x  one two\n" +
+    "   three four\n" +
+    "   five\n",
+  },
+  { "maximum width of other column",
+    &argv.Table{Width: 40, Format: []argv.ColumnFormat{{MaxWidth: 4}}, Rows: [][]string{
+      {"abc", "x"},
+      {"overlong", "y"},
+    }},
+    "abc   x\n" +
+    "overlong  \n" +
+    "      y\n",
+  },
+  { "more than 8 columns, plain line, multi-line cells",
+    &argv.Table{Width: 80, Separator: " ", Rows: [][]string{
+      {"-rw-r--r--", "1", "root", "root", "4096", "Jan", "1", "12:00", "a.txt"},
+      {"Directory /tmp:"},
+      {"drwxr-xr-x", "2", "user", "users", "40", "Dec", "24", "2015", "sub\ndir"},
+    }},
+    "-rw-r--r-- 1 root root  4096 Jan 1  12:00 a.txt\n" +
+    "Directory /tmp:\n" +
+    "drwxr-xr-x 2 user users 40   Dec 24 2015  sub\n" +
+    "                                          dir\n",
+  },
+  { "last column on its own line",
+    &argv.Table{Width: 20, Format: []argv.ColumnFormat{{}, {MinWidth: 10}}, Rows: [][]string{
+      {"--long-option", "Text of the last column."},
+    }},
+    "--long-option  \n" +
+    "     Text of the\n" +
+    "     last column.\n",
+  },
+}
+
+type failwriter int
+
+func (f *failwriter) Write(b []byte) (int, error) {
+  if *f <= 0 { return 0, errors.New("disk full") }
+  if len(b) > int(*f) { b = b[:*f] }
+  *f -= failwriter(len(b))
+  return len(b), nil
+}
+
+func main() {
+  for _, t := range tests {
+    fmt.Printf("%v ... ", t.name)
+    var buf bytes.Buffer
+    n, err := t.table.WriteTo(&buf)
+    if err != nil || n != int64(buf.Len()) || buf.String() != t.expected || t.table.String() != t.expected {
+      fmt.Printf("FAIL\n%v\n%q\nexpected\n%q\n", err, buf.String(), t.expected)
+      os.Exit(1)
+    }
+    fmt.Println("OK")
+  }
+  
+  fmt.Printf("write error ... ")
+  f := failwriter(10)
+  n, err := tests[0].table.WriteTo(&f)
+  if n != 10 || err == nil || err.Error() != "disk full" {
+    fmt.Printf("FAIL (%v, %v)\n", n, err)
+    os.Exit(1)
+  }
+  fmt.Println("OK")
+}